@@ -0,0 +1,294 @@
+// Package baseline records the dependency cycles and rule violations that
+// are already known, so that only new ones fail a build.
+//
+// Cycles are identified by the dependencies within strongly connected
+// components rather than by the paths reported for them, as those depend
+// on where the cycle search happened to start. A cycle is new if it
+// contains a dependency that wasn't part of any cycle in the baseline.
+package baseline
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/rules"
+)
+
+// Version is the version of the baseline file format.
+const Version = 1
+
+// Baseline is a snapshot of the cycles and rule violations of a graph.
+type Baseline struct {
+	Version    int         `json:"version"`
+	Cycles     []Cycle     `json:"cycles"`
+	Violations []Violation `json:"violations"`
+}
+
+// Cycle is a strongly connected component along with the dependencies
+// within it. ID is derived from the dependencies, so it's stable as long
+// as they don't change.
+type Cycle struct {
+	ID      string   `json:"id"`
+	Classes []string `json:"classes"`
+	Edges   []Edge   `json:"edges"`
+}
+
+// Edge is a dependency that is part of a cycle.
+type Edge = dependency.Edge
+
+// Violation identifies a rule violation.
+type Violation struct {
+	Rule string `json:"rule"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Diff holds what changed since the baseline was recorded.
+type Diff struct {
+	NewCycles          []Cycle
+	ResolvedCycles     []Cycle
+	NewViolations      []Violation
+	ResolvedViolations []Violation
+}
+
+// Failed returns true if there are new cycles or violations.
+func (d Diff) Failed() bool {
+	return len(d.NewCycles) != 0 || len(d.NewViolations) != 0
+}
+
+// New takes a snapshot of the cycles of the graph and the given rule
+// violations.
+func New(d *dependency.Dependency, violations []rules.Violation) *Baseline {
+	b := Baseline{
+		Version:    Version,
+		Cycles:     make([]Cycle, 0),
+		Violations: make([]Violation, 0),
+	}
+
+	for _, scc := range d.StronglyConnectedComponents() {
+		members := make(map[string]struct{}, len(scc))
+		for _, class := range scc {
+			members[class] = struct{}{}
+		}
+
+		var edges []Edge
+		for _, from := range scc {
+			for _, to := range d.Dependencies(from) {
+				if _, ok := members[to]; ok {
+					edges = append(edges, Edge{From: from, To: to})
+				}
+			}
+		}
+
+		b.Cycles = append(b.Cycles, newCycle(scc, edges))
+	}
+
+	for _, v := range violations {
+		b.Violations = append(b.Violations, Violation{Rule: v.Rule, From: v.From, To: v.To})
+	}
+
+	sortViolations(b.Violations)
+
+	return &b
+}
+
+func newCycle(classes []string, edges []Edge) Cycle {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+
+		return edges[i].To < edges[j].To
+	})
+
+	h := sha1.New()
+	for _, e := range edges {
+		fmt.Fprintf(h, "%s\x00%s\n", e.From, e.To)
+	}
+
+	return Cycle{
+		ID:      hex.EncodeToString(h.Sum(nil))[:12],
+		Classes: classes,
+		Edges:   edges,
+	}
+}
+
+func sortViolations(violations []Violation) {
+	sort.Slice(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+
+		if a.From != b.From {
+			return a.From < b.From
+		}
+
+		return a.To < b.To
+	})
+}
+
+// Compare reports the cycles and violations of current that are not in
+// the baseline, as well as the ones in the baseline that are gone.
+//
+// Cycles are compared the same way as by dependency.Compare, see
+// dependency.CompareCycles.
+func (b *Baseline) Compare(current *Baseline) Diff {
+	var diff Diff
+
+	added, resolved := dependency.CompareCycles(b.cycleEdges(), current.cycleEdges())
+	for _, i := range added {
+		diff.NewCycles = append(diff.NewCycles, current.Cycles[i])
+	}
+	for _, i := range resolved {
+		diff.ResolvedCycles = append(diff.ResolvedCycles, b.Cycles[i])
+	}
+
+	diff.NewViolations = subtract(current.Violations, b.Violations)
+	diff.ResolvedViolations = subtract(b.Violations, current.Violations)
+
+	return diff
+}
+
+// Tighten drops everything from the baseline that is not present in
+// current anymore, so that it can't come back unnoticed. Cycles keep only
+// the dependencies that are still part of a cycle.
+func (b *Baseline) Tighten(current *Baseline) {
+	remaining := current.edges()
+
+	cycles := make([]Cycle, 0, len(b.Cycles))
+	for _, c := range b.Cycles {
+		var (
+			edges   []Edge
+			classes = make(map[string]struct{})
+		)
+
+		for _, e := range c.Edges {
+			if _, ok := remaining[e]; ok {
+				edges = append(edges, e)
+				classes[e.From] = struct{}{}
+				classes[e.To] = struct{}{}
+			}
+		}
+
+		if len(edges) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(classes))
+		for class := range classes {
+			names = append(names, class)
+		}
+
+		sort.Strings(names)
+
+		cycles = append(cycles, newCycle(names, edges))
+	}
+
+	b.Cycles = cycles
+
+	resolved := subtract(b.Violations, current.Violations)
+	b.Violations = subtract(b.Violations, resolved)
+}
+
+func (b *Baseline) cycleEdges() [][]Edge {
+	edges := make([][]Edge, 0, len(b.Cycles))
+	for _, c := range b.Cycles {
+		edges = append(edges, c.Edges)
+	}
+
+	return edges
+}
+
+func (b *Baseline) edges() map[Edge]struct{} {
+	edges := make(map[Edge]struct{})
+	for _, c := range b.Cycles {
+		for _, e := range c.Edges {
+			edges[e] = struct{}{}
+		}
+	}
+
+	return edges
+}
+
+// subtract returns the violations of a that are not in b.
+func subtract(a, b []Violation) []Violation {
+	known := make(map[Violation]struct{}, len(b))
+	for _, v := range b {
+		known[v] = struct{}{}
+	}
+
+	res := make([]Violation, 0)
+	for _, v := range a {
+		if _, ok := known[v]; !ok {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// Write writes the baseline as indented JSON.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(b)
+}
+
+// WriteFile writes the baseline to the given file, replacing it if it
+// already exists.
+func (b *Baseline) WriteFile(path string) error {
+	const op = "baseline.WriteFile"
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%v: failed to create %q: %v", op, path, err)
+	}
+
+	err = b.Write(file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return fmt.Errorf("%v: failed to write %q: %v", op, path, err)
+	}
+
+	return nil
+}
+
+// Read reads a baseline written by Write.
+func Read(r io.Reader) (*Baseline, error) {
+	const op = "baseline.Read"
+
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("%v: decode: %v", op, err)
+	}
+
+	if b.Version != Version {
+		return nil, fmt.Errorf("%v: unsupported version: %d", op, b.Version)
+	}
+
+	return &b, nil
+}
+
+// ReadFile reads a baseline from the given file.
+func ReadFile(path string) (*Baseline, error) {
+	const op = "baseline.ReadFile"
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%v: failed to open %q: %v", op, path, err)
+	}
+	defer file.Close()
+
+	return Read(file)
+}
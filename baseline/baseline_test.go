@@ -0,0 +1,130 @@
+package baseline
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/rules"
+)
+
+func graph(edges ...[2]string) *dependency.Dependency {
+	d := dependency.NewWithCycles(true)
+	for _, e := range edges {
+		d.Add(e[0], e[1])
+	}
+
+	return d
+}
+
+func TestNew(t *testing.T) {
+	d := graph([2]string{"b", "a"}, [2]string{"a", "b"}, [2]string{"a", "c"})
+	violations := []rules.Violation{
+		{Rule: "z", From: "a", To: "c", Message: "ignored"},
+		{Rule: "y", From: "a", To: "b"},
+	}
+
+	b := New(d, violations)
+
+	if len(b.Cycles) != 1 {
+		t.Fatalf("New() recorded %d cycles, want 1", len(b.Cycles))
+	}
+
+	if want := []Edge{{From: "a", To: "b"}, {From: "b", To: "a"}}; !reflect.DeepEqual(b.Cycles[0].Edges, want) {
+		t.Errorf("cycle edges = %v, want %v", b.Cycles[0].Edges, want)
+	}
+
+	if want := []Violation{{"y", "a", "b"}, {"z", "a", "c"}}; !reflect.DeepEqual(b.Violations, want) {
+		t.Errorf("violations = %v, want %v", b.Violations, want)
+	}
+
+	// The id only depends on the dependencies, not on the order they
+	// were added in.
+	again := New(graph([2]string{"a", "c"}, [2]string{"a", "b"}, [2]string{"b", "a"}), nil)
+	if again.Cycles[0].ID != b.Cycles[0].ID {
+		t.Errorf("cycle id changed between runs: %s != %s", again.Cycles[0].ID, b.Cycles[0].ID)
+	}
+}
+
+func TestBaseline_Compare(t *testing.T) {
+	base := New(graph(
+		[2]string{"a", "b"}, [2]string{"b", "a"},
+		[2]string{"c", "d"}, [2]string{"d", "c"},
+	), []rules.Violation{{Rule: "r", From: "a", To: "b"}, {Rule: "r", From: "c", To: "d"}})
+
+	current := New(graph(
+		[2]string{"a", "b"}, [2]string{"b", "a"},
+		[2]string{"c", "d"},
+		[2]string{"e", "f"}, [2]string{"f", "e"},
+	), []rules.Violation{{Rule: "r", From: "a", To: "b"}, {Rule: "r", From: "e", To: "f"}})
+
+	diff := base.Compare(current)
+
+	if len(diff.NewCycles) != 1 || diff.NewCycles[0].Classes[0] != "e" {
+		t.Errorf("new cycles = %+v, want the e-f one", diff.NewCycles)
+	}
+
+	if len(diff.ResolvedCycles) != 1 || diff.ResolvedCycles[0].Classes[0] != "c" {
+		t.Errorf("resolved cycles = %+v, want the c-d one", diff.ResolvedCycles)
+	}
+
+	if want := []Violation{{"r", "e", "f"}}; !reflect.DeepEqual(diff.NewViolations, want) {
+		t.Errorf("new violations = %v, want %v", diff.NewViolations, want)
+	}
+
+	if want := []Violation{{"r", "c", "d"}}; !reflect.DeepEqual(diff.ResolvedViolations, want) {
+		t.Errorf("resolved violations = %v, want %v", diff.ResolvedViolations, want)
+	}
+
+	if !diff.Failed() {
+		t.Errorf("Diff.Failed() = false, want true")
+	}
+
+	if base.Compare(base).Failed() {
+		t.Errorf("comparing the baseline to itself failed")
+	}
+}
+
+func TestBaseline_Tighten(t *testing.T) {
+	base := New(graph(
+		[2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"c", "a"}, [2]string{"b", "a"},
+		[2]string{"x", "y"}, [2]string{"y", "x"},
+	), []rules.Violation{{Rule: "r", From: "a", To: "b"}, {Rule: "r", From: "x", To: "y"}})
+
+	current := New(graph(
+		[2]string{"a", "b"}, [2]string{"b", "a"},
+	), []rules.Violation{{Rule: "r", From: "a", To: "b"}})
+
+	base.Tighten(current)
+
+	if len(base.Cycles) != 1 || !reflect.DeepEqual(base.Cycles[0], current.Cycles[0]) {
+		t.Errorf("tightened cycles = %+v, want %+v", base.Cycles, current.Cycles)
+	}
+
+	if !reflect.DeepEqual(base.Violations, current.Violations) {
+		t.Errorf("tightened violations = %v, want %v", base.Violations, current.Violations)
+	}
+}
+
+func TestBaseline_roundTrip(t *testing.T) {
+	want := New(graph([2]string{"a", "b"}, [2]string{"b", "a"}), []rules.Violation{{Rule: "r", From: "a", To: "b"}})
+
+	var buf bytes.Buffer
+	if err := want.Write(&buf); err != nil {
+		t.Fatalf("Baseline.Write() error = %v", err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+
+	if _, err := Read(bytes.NewBufferString(`{"version": 42}`)); err == nil {
+		t.Errorf("Read() expected error for unknown version")
+	}
+}
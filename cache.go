@@ -0,0 +1,194 @@
+package depser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cacheVersion changes whenever what is extracted from the source files
+// does, so that caches written by older versions are ignored.
+const cacheVersion = 3
+
+// Cache keeps what was extracted from each source file on disk, so that
+// rescans only need to parse the files that changed. A file is taken from
+// the cache if its size and modification time are unchanged, or if its
+// content hashes to the same value.
+type Cache struct {
+	rw      *sync.RWMutex
+	path    string
+	entries map[string]cacheEntry
+	used    map[string]struct{}
+
+	hits, misses int
+}
+
+type cacheFile struct {
+	Version int                   `json:"version"`
+	Entries map[string]cacheEntry `json:"entries"`
+}
+
+type cacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"`
+	Hash    string `json:"hash"`
+	Source  source `json:"source"`
+}
+
+// NewCache returns an empty cache that is only kept in memory, e.g. to
+// rescan the same sources repeatedly.
+func NewCache() *Cache {
+	return &Cache{
+		rw:      &sync.RWMutex{},
+		entries: make(map[string]cacheEntry),
+		used:    make(map[string]struct{}),
+	}
+}
+
+// OpenCache loads the cache stored at path. A missing cache, or one that
+// was written by another version, is treated as empty.
+func OpenCache(path string) (*Cache, error) {
+	const op = "OpenCache"
+
+	c := NewCache()
+	c.path = path
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", op, err)
+	}
+
+	var stored cacheFile
+	if err := json.Unmarshal(data, &stored); err == nil && stored.Version == cacheVersion && stored.Entries != nil {
+		c.entries = stored.Entries
+	}
+
+	return c, nil
+}
+
+// Save writes the entries of the files that were scanned since the cache
+// was opened back to disk, dropping the rest.
+func (c *Cache) Save() error {
+	const op = "Cache.Save"
+
+	if c.path == "" {
+		return fmt.Errorf("%v: the cache is only kept in memory", op)
+	}
+
+	c.rw.RLock()
+	stored := cacheFile{Version: cacheVersion, Entries: make(map[string]cacheEntry, len(c.used))}
+	for path := range c.used {
+		stored.Entries[path] = c.entries[path]
+	}
+	c.rw.RUnlock()
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("%v: %v", op, err)
+	}
+
+	// Write to a temporary file first, so that an interrupted save
+	// doesn't leave a truncated cache behind.
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("%v: %v", op, err)
+	}
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("%v: %v", op, err)
+	}
+
+	return nil
+}
+
+// Stats returns how many files were taken from the cache, and how many had
+// to be parsed since it was opened.
+func (c *Cache) Stats() (hits, misses int) {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return c.hits, c.misses
+}
+
+// source returns what was extracted from the file, parsing it only if it
+// changed since it was cached.
+func (c *Cache) source(path string, info os.FileInfo) (source, error) {
+	c.rw.RLock()
+	entry, ok := c.entries[path]
+	c.rw.RUnlock()
+
+	if ok && entry.Size == info.Size() && entry.ModTime == info.ModTime().UnixNano() {
+		c.store(path, entry, true)
+		return entry.Source, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return source{}, err
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	hit := ok && entry.Hash == hash
+	if !hit {
+		if entry.Source, err = extractSource(path, content); err != nil {
+			return source{}, err
+		}
+	}
+
+	entry.Size = info.Size()
+	entry.ModTime = info.ModTime().UnixNano()
+	entry.Hash = hash
+
+	c.store(path, entry, hit)
+
+	return entry.Source, nil
+}
+
+func (c *Cache) store(path string, entry cacheEntry, hit bool) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.entries[path] = entry
+	c.used[path] = struct{}{}
+
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+}
+
+// readSource extracts the source file, through the cache if there is one.
+func readSource(cache *Cache, path string, info os.FileInfo) (source, error) {
+	if cache != nil {
+		return cache.source(path, info)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return source{}, err
+	}
+
+	return extractSource(path, content)
+}
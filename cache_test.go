@@ -0,0 +1,130 @@
+package depser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	root := writeSources(t, map[string]string{
+		"com/a/A.java": "package com.a;\n\nimport com.b.B; // depser:ignore-cycle legacy\n\npublic class A {}\n",
+		"com/b/B.java": "package com.b;\n\nimport com.a.A;\n\npublic abstract class B {}\n",
+	})
+	defer os.RemoveAll(root)
+
+	cachePath := filepath.Join(root, "cache.json")
+
+	build := func(wantHits, wantMisses int) {
+		t.Helper()
+
+		cache, err := OpenCache(cachePath)
+		if err != nil {
+			t.Fatalf("OpenCache() error = %v", err)
+		}
+
+		d, err := BuildDependenciesWithOptions([]string{root}, Options{Cache: cache})
+		if err != nil {
+			t.Fatalf("BuildDependenciesWithOptions() error = %v", err)
+		}
+
+		if hits, misses := cache.Stats(); hits != wantHits || misses != wantMisses {
+			t.Errorf("Stats() = %d hits, %d misses, want %d and %d", hits, misses, wantHits, wantMisses)
+		}
+
+		if got := d.Dependencies("com.b.B"); !reflect.DeepEqual(got, []string{"com.a.A"}) {
+			t.Errorf("dependencies of B = %v, want [com.a.A]", got)
+		}
+
+		if reason, ok := d.Suppressed("com.a.A", "com.b.B"); !ok || reason != "legacy" {
+			t.Errorf("Suppressed(A, B) = %q, %v, want the cached suppression", reason, ok)
+		}
+
+		if n, _ := d.Node("com.b.B"); !n.Abstract {
+			t.Errorf("B is not abstract, want the cached declaration")
+		}
+
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	build(0, 2)
+	build(2, 0)
+
+	// Touching a file without changing it is caught by the hash.
+	a := filepath.Join(root, "com", "a", "A.java")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(a, later, later); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	build(2, 0)
+
+	b := filepath.Join(root, "com", "b", "B.java")
+	if err := ioutil.WriteFile(b, []byte("package com.b;\n\nimport com.a.A;\n\npublic abstract class B { }\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	build(1, 1)
+}
+
+func TestOpenCache_stale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing", ""},
+		{"corrupt", "{"},
+		{"other version", `{"version": 0, "entries": {"A.java": {"size": 1}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if tt.content != "" {
+				if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+
+			cache, err := OpenCache(path)
+			if err != nil {
+				t.Fatalf("OpenCache() error = %v", err)
+			}
+
+			if len(cache.entries) != 0 {
+				t.Errorf("OpenCache() loaded %d entries, want none", len(cache.entries))
+			}
+		})
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	root := writeSources(t, map[string]string{"com/a/A.java": "package com.a;\n\npublic class A {}\n"})
+	defer os.RemoveAll(root)
+
+	cache := NewCache()
+
+	for i := 0; i < 2; i++ {
+		if _, err := BuildDependenciesWithOptions([]string{root}, Options{Cache: cache}); err != nil {
+			t.Fatalf("BuildDependenciesWithOptions() error = %v", err)
+		}
+	}
+
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("Stats() = %d hits, %d misses, want 1 and 1", hits, misses)
+	}
+
+	if err := cache.Save(); err == nil {
+		t.Errorf("Save() of an in-memory cache expected error")
+	}
+}
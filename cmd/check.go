@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+
+	"github.com/djavorszky/depser/baseline"
+	"github.com/djavorszky/depser/findings"
+	"github.com/djavorszky/depser/rules"
+)
+
+func runCheck(args []string) {
+	c := newFlags("check", "Fail on dependency cycles and architecture rule violations.", "paths...", "text", "json", "csv", "sarif", "junit")
+	rulesFile := c.fs.String("rules", defaultRulesFile, "file to read the architecture rules from, none if empty")
+	baselineFile := c.fs.String("baseline", "", "only fail on cycles and violations not recorded in this baseline")
+	tighten := c.fs.Bool("tighten", false, "drop resolved cycles and violations from the baseline")
+	showSuppressed := c.fs.Bool("show-suppressed", false, "log the suppressed cycles and violations")
+
+	c.parse(args)
+
+	config := mustLoadRules(*rulesFile)
+	dep := c.mustLoadGraph(c.fs.Args())
+	violations := config.Check(dep)
+	current := baseline.New(dep, violations)
+
+	if *showSuppressed {
+		printSuppressions(dep.SuppressedCycleEdges())
+
+		for _, v := range config.Suppressed(dep) {
+			progress.Printf("%s:%d: Suppressed rule violation [%s]: %s (%s)\n", v.File, v.Line, v.Rule, v.Message, v.Reason)
+		}
+	}
+
+	if *baselineFile == "" {
+		writeFindings(c.format, append(
+			findings.Cycles(dep, current.Cycles),
+			findings.Violations(current.Violations, violations)...,
+		), dep, config)
+
+		if len(current.Cycles) != 0 || len(current.Violations) != 0 {
+			progress.Printf("%d cycle(s) and %d rule violation(s) found\n", len(current.Cycles), len(current.Violations))
+			os.Exit(exitFindings)
+		}
+
+		return
+	}
+
+	base, err := baseline.ReadFile(*baselineFile)
+	if err != nil {
+		fail(exitUsage, "Failed reading baseline: %v", err)
+	}
+
+	diff := base.Compare(current)
+
+	var found []findings.Finding
+	found = append(found, findings.Cycles(dep, diff.NewCycles)...)
+	found = append(found, findings.Violations(diff.NewViolations, violations)...)
+	found = append(found, findings.Resolve(findings.Cycles(dep, diff.ResolvedCycles))...)
+	found = append(found, findings.Resolve(findings.Violations(diff.ResolvedViolations, nil))...)
+
+	writeFindings(c.format, found, dep, config)
+
+	if *tighten {
+		base.Tighten(current)
+
+		if err := base.WriteFile(*baselineFile); err != nil {
+			fail(exitFailure, "Failed tightening baseline: %v", err)
+		}
+	}
+
+	if diff.Failed() {
+		progress.Printf("%d new cycle(s) and %d new rule violation(s) found\n", len(diff.NewCycles), len(diff.NewViolations))
+		os.Exit(exitFindings)
+	}
+}
+
+func runBaseline(args []string) {
+	if len(args) == 0 || args[0] != "write" {
+		fail(exitUsage, "Usage: depser baseline write [flags] paths...")
+	}
+
+	c := newFlags("baseline write", "Record the current cycles and rule violations as accepted.", "paths...")
+	rulesFile := c.fs.String("rules", defaultRulesFile, "file to read the architecture rules from, none if empty")
+	output := c.fs.String("o", "depser-baseline.json", "file to write the baseline to")
+
+	c.parse(args[1:])
+
+	config := mustLoadRules(*rulesFile)
+	dep := c.mustLoadGraph(c.fs.Args())
+	current := baseline.New(dep, config.Check(dep))
+
+	if err := current.WriteFile(*output); err != nil {
+		fail(exitFailure, "Failed writing baseline: %v", err)
+	}
+
+	progress.Printf("Baseline with %d cycle(s) and %d rule violation(s) written to %s\n", len(current.Cycles), len(current.Violations), *output)
+}
+
+// defaultRulesFile is where the architecture rules are read from, if the
+// file exists and -rules isn't given.
+const defaultRulesFile = "depser.yaml"
+
+// mustLoadRules loads the rules in rulesFile, or returns an empty set of
+// rules if it's not set, or it's the default one and doesn't exist, as
+// projects without rules are still checked for cycles. It exits on failure.
+func mustLoadRules(rulesFile string) *rules.Config {
+	if rulesFile == "" {
+		return &rules.Config{}
+	}
+
+	if _, err := os.Stat(rulesFile); os.IsNotExist(err) && rulesFile == defaultRulesFile {
+		return &rules.Config{}
+	}
+
+	config, err := rules.Load(rulesFile)
+	if err != nil {
+		fail(exitUsage, "Failed loading rules: %v", err)
+	}
+
+	return config
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/djavorszky/depser"
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/findings"
+	"github.com/djavorszky/depser/rules"
+)
+
+// Exit codes, so that scripts can tell findings apart from failures.
+const (
+	exitFindings = 1 // cycles, violations or other findings were reported
+	exitUsage    = 2 // invalid command, flags, arguments or configuration
+	exitParse    = 3 // the sources or the saved graph couldn't be read
+	exitFailure  = 4 // anything else, like failing to write the output
+)
+
+// progress logs what the commands are doing to stderr, unless -quiet is
+// set. Results go to stdout, errors are always logged.
+var progress = log.New(os.Stderr, "", log.LstdFlags)
+
+// commonFlags are the flags shared by the commands working on a graph.
+type commonFlags struct {
+	fs *flag.FlagSet
+
+	fileName   string
+	graphFile  string
+	cacheFile  string
+	format     string
+	formats    []string
+	quiet      bool
+	exclude    listFlag
+	jdk        string
+	thirdParty string
+}
+
+// listFlag is a flag that can be repeated, or given a comma separated
+// list of values.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+// newFlags sets up the flag set of a command, along with the common flags.
+// Formats lists the output formats the command supports, the first of
+// which is the default. Args describes the positional arguments.
+func newFlags(name, summary, args string, formats ...string) *commonFlags {
+	c := commonFlags{fs: flag.NewFlagSet(name, flag.ExitOnError), formats: formats}
+
+	c.fs.StringVar(&c.fileName, "f", "", "file listing the source roots to scan, one per line")
+	c.fs.StringVar(&c.graphFile, "graph", "", "load a graph saved as json or jsonl instead of scanning sources")
+	c.fs.StringVar(&c.cacheFile, "cache", "", "keep what was parsed from the sources in this file, to only parse changed files next time")
+	c.fs.Var(&c.exclude, "exclude", "skip files and directories matching this glob (repeatable, comma separated)")
+	c.fs.StringVar(&c.jdk, "jdk", "keep", "what to do with imported JDK classes: keep, drop, or collapse them to their packages")
+	c.fs.StringVar(&c.thirdParty, "third-party", "keep", "what to do with imported third-party classes: keep, drop, or collapse them to their packages")
+	c.fs.BoolVar(&c.quiet, "quiet", false, "only log errors")
+
+	if len(formats) != 0 {
+		c.fs.StringVar(&c.format, "format", formats[0], "output format: "+strings.Join(formats, ", "))
+	}
+
+	c.fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: depser %s [flags] %s\n\n%s\n\nFlags:\n", name, args, summary)
+		c.fs.PrintDefaults()
+	}
+
+	return &c
+}
+
+// parse parses the arguments, and checks the common flags. It exits on
+// invalid ones.
+func (c *commonFlags) parse(args []string) {
+	c.fs.Parse(args)
+
+	if c.quiet {
+		progress.SetOutput(ioutil.Discard)
+	}
+
+	if c.format != "" && !contains(c.formats, c.format) {
+		c.usageError("Unknown format: %s", c.format)
+	}
+
+	for _, pattern := range c.exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			c.usageError("Invalid exclude pattern %q: %v", pattern, err)
+		}
+	}
+
+	for name, value := range map[string]string{"jdk": c.jdk, "third-party": c.thirdParty} {
+		switch depser.External(value) {
+		case depser.ExternalKeep, depser.ExternalDrop, depser.ExternalCollapse:
+		default:
+			c.usageError("Unknown value for -%s: %s", name, value)
+		}
+	}
+}
+
+// usageError logs the error along with the usage of the command, and
+// exits.
+func (c *commonFlags) usageError(format string, args ...interface{}) {
+	log.Printf(format+"\n\n", args...)
+	c.fs.Usage()
+	os.Exit(exitUsage)
+}
+
+// mustLoadGraph either loads a previously saved graph, or builds one by
+// scanning the source roots. It exits on failure.
+func (c *commonFlags) mustLoadGraph(args []string) *dependency.Dependency {
+	if c.graphFile != "" {
+		return c.mustReadGraph(c.graphFile)
+	}
+
+	return c.mustBuild(c.mustRoots(args))
+}
+
+// mustReadGraph loads a saved graph, dropping or collapsing its external
+// classes the same way scanning would. It exits on failure.
+func (c *commonFlags) mustReadGraph(fileName string) *dependency.Dependency {
+	dep, err := readGraph(fileName)
+	if err != nil {
+		fail(exitParse, "Failed loading graph: %v", err)
+	}
+
+	if err := depser.ReduceExternal(dep, c.scanOptions()); err != nil {
+		fail(exitUsage, "Failed reducing external classes: %v", err)
+	}
+
+	return dep
+}
+
+// mustRoots returns the source roots given as arguments, along with the
+// ones listed in the -f file. It exits if there are none.
+func (c *commonFlags) mustRoots(args []string) []string {
+	roots := append([]string(nil), args...)
+
+	if c.fileName != "" {
+		listed, err := parseFile(c.fileName)
+		if err != nil {
+			c.usageError("Failed parsing file: %v", err)
+		}
+
+		roots = append(roots, listed...)
+	}
+
+	if len(roots) == 0 {
+		c.usageError("Please specify one or more paths to scan, or a file listing them with -f")
+	}
+
+	return roots
+}
+
+// scanOptions returns the options to scan the sources with, without the
+// cache, e.g. for sources exported from git.
+func (c *commonFlags) scanOptions() depser.Options {
+	return depser.Options{
+		AllowCycles: true,
+		Exclude:     c.exclude,
+		JDK:         depser.External(c.jdk),
+		ThirdParty:  depser.External(c.thirdParty),
+	}
+}
+
+// mustOptions returns the options to scan the sources with, opening the
+// cache if one was given. It exits on failure.
+func (c *commonFlags) mustOptions() depser.Options {
+	options := c.scanOptions()
+
+	if c.cacheFile != "" {
+		cache, err := depser.OpenCache(c.cacheFile)
+		if err != nil {
+			fail(exitFailure, "Failed opening cache: %v", err)
+		}
+
+		options.Cache = cache
+	}
+
+	return options
+}
+
+// mustBuild scans the source roots. It exits on failure.
+func (c *commonFlags) mustBuild(roots []string) *dependency.Dependency {
+	options := c.mustOptions()
+
+	start := time.Now()
+
+	dep, err := depser.BuildDependenciesWithOptions(roots, options)
+	if err != nil {
+		fail(exitParse, "Failed building dependencies: %v", err)
+	}
+
+	progress.Printf("Scanned %d source root(s) in %s\n", len(roots), time.Since(start))
+
+	if options.Cache != nil {
+		hits, misses := options.Cache.Stats()
+		progress.Printf("Parsed %d file(s), %d unchanged since the last scan\n", misses, hits)
+
+		if err := options.Cache.Save(); err != nil {
+			fail(exitFailure, "Failed saving cache: %v", err)
+		}
+	}
+
+	return dep
+}
+
+// fail logs the error and exits with code.
+func fail(code int, format string, args ...interface{}) {
+	log.Printf(format+"\n", args...)
+	os.Exit(code)
+}
+
+// mustWrite exits if writing the results failed.
+func mustWrite(err error) {
+	if err != nil {
+		fail(exitFailure, "Failed writing results: %v", err)
+	}
+}
+
+// writeJSON writes v to stdout as indented JSON.
+func writeJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// writeCSV writes the header and the rows to stdout as CSV.
+func writeCSV(header []string, rows [][]string) error {
+	cw := csv.NewWriter(os.Stdout)
+
+	cw.Write(header)
+	cw.WriteAll(rows)
+
+	return cw.Error()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readGraph(fileName string) (*dependency.Dependency, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening file: %v", err)
+	}
+	defer file.Close()
+
+	if strings.HasSuffix(fileName, ".jsonl") {
+		return dependency.ReadJSONLines(file)
+	}
+
+	return dependency.ReadJSON(file)
+}
+
+func parseFile(fileName string) ([]string, error) {
+	var sources []string
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening file: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			sources = append(sources, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading from io.Reader: %v", err)
+	}
+
+	return sources, nil
+}
+
+// mustSaveGraph writes dep to output, as jsonl if it ends with .jsonl and
+// as json otherwise.
+func mustSaveGraph(dep *dependency.Dependency, output string) {
+	out := mustCreate(output)
+
+	var err error
+	if strings.HasSuffix(output, ".jsonl") {
+		err = dep.WriteJSONLines(out)
+	} else {
+		err = dep.WriteJSON(out)
+	}
+
+	if err != nil {
+		fail(exitFailure, "Failed saving graph: %v", err)
+	}
+
+	mustClose(out)
+
+	progress.Printf("Graph saved to %s\n", output)
+}
+
+// mustCreate opens the output file, or returns stdout if it's not set.
+// It exits on failure.
+func mustCreate(output string) *os.File {
+	if output == "" {
+		return os.Stdout
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		fail(exitFailure, "Failed creating output file: %v", err)
+	}
+
+	return out
+}
+
+// mustClose closes the output file, unless it's stdout. It exits on
+// failure, as that means the output may be incomplete.
+func mustClose(out *os.File) {
+	if out == os.Stdout {
+		return
+	}
+
+	if err := out.Close(); err != nil {
+		fail(exitFailure, "Failed writing output file: %v", err)
+	}
+}
+
+// writeFindings writes the findings to stdout in the chosen format. The
+// packages of dep and the rules of config are needed for junit, to report
+// what passed; config may be nil if no rules were checked.
+func writeFindings(format string, fs []findings.Finding, dep *dependency.Dependency, config *rules.Config) {
+	var err error
+
+	switch format {
+	case "text":
+		err = findings.WriteText(os.Stdout, fs)
+	case "json":
+		err = findings.WriteJSON(os.Stdout, fs)
+	case "csv":
+		err = findings.WriteCSV(os.Stdout, fs)
+	case "sarif":
+		err = findings.WriteSARIF(os.Stdout, fs)
+	case "junit":
+		var names []string
+		if config != nil {
+			names = config.Names()
+		}
+
+		err = findings.WriteJUnit(os.Stdout, fs, declaredPackages(dep), names)
+	}
+
+	mustWrite(err)
+}
+
+// declaredPackages returns the packages of the classes found in the
+// sources, leaving out the ones that were only imported.
+func declaredPackages(dep *dependency.Dependency) []string {
+	seen := make(map[string]struct{})
+
+	var packages []string
+	for _, class := range dep.Classes() {
+		if _, ok := dep.Node(class); !ok {
+			continue
+		}
+
+		p := dependency.PackageOf(class)
+		if _, ok := seen[p]; !ok {
+			seen[p] = struct{}{}
+			packages = append(packages, p)
+		}
+	}
+
+	return packages
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+func runCompare(args []string) {
+	c := newFlags("compare", "Compare a saved graph to the current one, e.g. to follow how coupling changes over time.", "<before> paths...", "text", "json")
+
+	c.parse(args)
+
+	if c.fs.NArg() < 1 {
+		c.usageError("Please specify the saved graph to compare to")
+	}
+
+	before := c.mustReadGraph(c.fs.Arg(0))
+	after := c.mustLoadGraph(c.fs.Args()[1:])
+
+	diff := dependency.Compare(before, after)
+
+	var err error
+
+	switch c.format {
+	case "text":
+		err = dependency.WriteGraphDiffText(os.Stdout, diff)
+	case "json":
+		err = dependency.WriteGraphDiffJSON(os.Stdout, diff)
+	}
+
+	mustWrite(err)
+}
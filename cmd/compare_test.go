@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+func TestCompare_external(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src", "a", "A.java")
+	os.MkdirAll(filepath.Dir(src), 0755)
+	if err := ioutil.WriteFile(src, []byte("package a;\n\nimport java.util.List;\nimport org.slf4j.Logger;\n\npublic class A {}\n"), 0644); err != nil {
+		t.Fatalf("failed writing %s: %v", src, err)
+	}
+
+	saved := filepath.Join(dir, "graph.json")
+	if code := runMain(t, dir, "scan", "-quiet", "-o", saved, "src"); code != 0 {
+		t.Fatalf("scan exit code = %d, want 0", code)
+	}
+
+	tests := [][]string{
+		nil,
+		{"-jdk", "drop"},
+		{"-jdk", "collapse", "-third-party", "collapse"},
+	}
+	for _, flags := range tests {
+		t.Run(fmt.Sprint(flags), func(t *testing.T) {
+			args := append([]string{"compare", "-quiet", "-format", "json"}, flags...)
+			code, out := runMainOutput(t, dir, append(args, saved, "src")...)
+			if code != 0 {
+				t.Fatalf("exit code = %d, want 0", code)
+			}
+
+			var got dependency.GraphDiff
+			if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatalf("failed parsing %q: %v", out, err)
+			}
+
+			if len(got.AddedDependencies) != 0 || len(got.RemovedDependencies) != 0 {
+				t.Errorf("added %v and removed %v, want no changes", got.AddedDependencies, got.RemovedDependencies)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/djavorszky/depser/baseline"
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/findings"
+)
+
+func runCycles(args []string) {
+	c := newFlags("cycles", "Report the dependency cycles between classes.", "paths...", "text", "json", "csv", "sarif", "junit")
+	suggest := c.fs.Bool("suggest", false, "suggest which imports to remove to break the cycles")
+	showSuppressed := c.fs.Bool("show-suppressed", false, "log the suppressed dependencies that would close cycles")
+
+	c.parse(args)
+
+	epoch := time.Now()
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	progress.Println("Checking cyclic dependencies")
+
+	start := time.Now()
+	cycles := findings.Cycles(dep, baseline.New(dep, nil).Cycles)
+
+	if *suggest {
+		findings.SuggestBreaks(dep, cycles, dep.FeedbackArcSets())
+	}
+
+	if *showSuppressed {
+		printSuppressions(dep.SuppressedCycleEdges())
+	}
+
+	progress.Printf("Cyclic dependency check done in %s\n", time.Since(start))
+	progress.Printf("Whole process took %s\n", time.Since(epoch))
+
+	writeFindings(c.format, cycles, dep, nil)
+
+	if len(cycles) != 0 {
+		progress.Printf("%d dependency cycle(s) detected\n", len(cycles))
+		os.Exit(exitFindings)
+	}
+}
+
+func printSuppressions(suppressions []dependency.Suppression) {
+	for _, s := range suppressions {
+		progress.Printf("Suppressed cycle dependency: %s -> %s (%s)\n", s.From, s.To, s.Reason)
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/djavorszky/depser"
+	"github.com/djavorszky/depser/baseline"
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/findings"
+	"github.com/djavorszky/depser/internal/git"
+	"github.com/djavorszky/depser/rules"
+)
+
+// graphDiff is the result of the diff command.
+type graphDiff struct {
+	Base     string             `json:"base"`
+	Head     string             `json:"head,omitempty"`
+	Added    []dependency.Edge  `json:"addedDependencies"`
+	Removed  []dependency.Edge  `json:"removedDependencies"`
+	Findings []findings.Finding `json:"findings"`
+}
+
+func runDiff(args []string) {
+	c := newFlags("diff", "Report the dependencies, cycles and rule violations the working tree, or the head revision, adds or removes compared to a git revision.", "-base <rev> [-head <rev>] paths...", "text", "json", "sarif")
+	base := c.fs.String("base", "", "git revision to compare the working tree to, e.g. origin/main")
+	head := c.fs.String("head", "", "git revision to compare to the base instead of the working tree, e.g. HEAD")
+	rulesFile := c.fs.String("rules", defaultRulesFile, "file to read the architecture rules from, none if empty")
+
+	c.parse(args)
+
+	if *base == "" {
+		c.usageError("Please specify the revision to compare to with -base")
+	}
+
+	if c.graphFile != "" {
+		c.usageError("A saved graph can't be compared to a git revision")
+	}
+
+	config := mustLoadRules(*rulesFile)
+	roots := c.mustRoots(c.fs.Args())
+
+	before, baseRoots := mustBuildRevision(c, *base, roots)
+
+	// The working tree is scanned as it is, uncommitted and untracked
+	// files included, unless a head revision is given.
+	var after *dependency.Dependency
+	headRoots := roots
+	if *head != "" {
+		after, headRoots = mustBuildRevision(c, *head, roots)
+	} else {
+		after = c.mustBuild(roots)
+	}
+
+	beforeViolations := config.Check(before)
+	afterViolations := config.Check(after)
+
+	// Files of the revisions are reported where they are in the working
+	// tree, as the exported copies are gone by now.
+	rebase(beforeViolations, baseRoots, roots)
+	rebase(afterViolations, headRoots, roots)
+
+	diff := baseline.New(before, beforeViolations).Compare(baseline.New(after, afterViolations))
+
+	changes := dependency.Compare(before, after)
+
+	result := graphDiff{
+		Base:    *base,
+		Head:    *head,
+		Added:   changes.AddedDependencies,
+		Removed: changes.RemovedDependencies,
+	}
+
+	result.Findings = append(result.Findings, rebaseCycles(findings.Cycles(after, diff.NewCycles), headRoots, roots)...)
+	result.Findings = append(result.Findings, findings.Violations(diff.NewViolations, afterViolations)...)
+	result.Findings = append(result.Findings, findings.Resolve(rebaseCycles(findings.Cycles(before, diff.ResolvedCycles), baseRoots, roots))...)
+	result.Findings = append(result.Findings, findings.Resolve(findings.Violations(diff.ResolvedViolations, beforeViolations))...)
+
+	switch c.format {
+	case "text":
+		mustWrite(writeDiffText(result))
+	case "json":
+		mustWrite(writeJSON(result))
+	case "sarif":
+		mustWrite(findings.WriteSARIF(os.Stdout, result.Findings))
+	}
+
+	progress.Printf("%d dependencies added, %d removed since %s\n", len(result.Added), len(result.Removed), *base)
+
+	if diff.Failed() {
+		progress.Printf("%d new cycle(s) and %d new rule violation(s) found\n", len(diff.NewCycles), len(diff.NewViolations))
+		os.Exit(exitFindings)
+	}
+}
+
+// mustBuildRevision scans the source roots as they are in the revision of
+// the git repository they are in. It returns the graph, along with where
+// the roots were while scanning. It exits on failure.
+func mustBuildRevision(c *commonFlags, rev string, roots []string) (*dependency.Dependency, []string) {
+	top, err := git.TopLevel(rootDir(roots[0]))
+	if err != nil {
+		c.usageError("Failed finding the git repository: %v", err)
+	}
+
+	rels := make([]string, 0, len(roots))
+	for _, root := range roots {
+		rel, err := relativeTo(top, root)
+		if err != nil {
+			c.usageError("%v", err)
+		}
+
+		rels = append(rels, rel)
+	}
+
+	tmp, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		fail(exitFailure, "Failed creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// Keep the name of the repository, as it may be the name of a module.
+	dir := filepath.Join(tmp, filepath.Base(top))
+
+	if err := git.Export(top, rev, dir, exported(rels)); err != nil {
+		os.RemoveAll(tmp)
+		fail(exitUsage, "Failed reading %s: %v", rev, err)
+	}
+
+	baseRoots := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		baseRoots = append(baseRoots, filepath.Join(dir, filepath.FromSlash(rel)))
+	}
+
+	// Roots that didn't exist in the revision yet have nothing to scan.
+	existing := make([]string, 0, len(baseRoots))
+	for _, root := range baseRoots {
+		if _, err := os.Stat(root); err == nil {
+			existing = append(existing, root)
+		}
+	}
+
+	dep, err := depser.BuildDependenciesWithOptions(existing, c.scanOptions())
+	if err != nil {
+		os.RemoveAll(tmp)
+		fail(exitParse, "Failed building dependencies of %s: %v", rev, err)
+	}
+
+	progress.Printf("Scanned %d source root(s) at %s\n", len(existing), rev)
+
+	return dep, baseRoots
+}
+
+// exported returns which files of the repository to export to scan the
+// roots at rels, which may be directories or single files: the sources
+// under them, and the build files telling which modules they are in.
+func exported(rels []string) func(file string) bool {
+	return func(file string) bool {
+		if depser.IsModuleMarker(path.Base(file)) {
+			return true
+		}
+
+		if !strings.HasSuffix(file, ".java") {
+			return false
+		}
+
+		for _, rel := range rels {
+			if rel == "." || file == rel || strings.HasPrefix(file, rel+"/") {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// rootDir returns the directory of the root, which may be a file.
+func rootDir(root string) string {
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		return filepath.Dir(root)
+	}
+
+	return root
+}
+
+// relativeTo returns the slash separated path of root within top.
+func relativeTo(top, root string) (string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	// The repository root is reported with symbolic links resolved.
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+
+	rel, err := filepath.Rel(top, abs)
+	if err != nil || outside(rel) {
+		return "", fmt.Errorf("%s is not in the git repository at %s", root, top)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// rebase moves the files of the violations from the from roots to the
+// to roots.
+func rebase(violations []rules.Violation, from, to []string) {
+	for i := range violations {
+		violations[i].File = rebasePath(violations[i].File, from, to)
+		rebaseProvenance(violations[i].Provenance, from, to)
+	}
+}
+
+func rebaseCycles(cycles []findings.Finding, from, to []string) []findings.Finding {
+	for _, c := range cycles {
+		for i := range c.Edges {
+			c.Edges[i].File = rebasePath(c.Edges[i].File, from, to)
+			rebaseProvenance(c.Edges[i].Provenance, from, to)
+		}
+	}
+
+	return cycles
+}
+
+func rebaseProvenance(records []dependency.Provenance, from, to []string) {
+	for i := range records {
+		records[i].File = rebasePath(records[i].File, from, to)
+	}
+}
+
+func rebasePath(file string, from, to []string) string {
+	for i, root := range from {
+		if rel, err := filepath.Rel(root, file); err == nil && !outside(rel) {
+			return filepath.Join(to[i], rel)
+		}
+	}
+
+	return file
+}
+
+// outside returns true if the relative path leads out of its base.
+func outside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func writeDiffText(d graphDiff) error {
+	for _, section := range []struct {
+		title string
+		edges []dependency.Edge
+	}{
+		{"Added dependencies", d.Added},
+		{"Removed dependencies", d.Removed},
+	} {
+		if len(section.edges) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s since %s:\n", section.title, d.Base)
+
+		for _, e := range section.edges {
+			if _, err := fmt.Printf("  %s -> %s\n", e.From, e.To); err != nil {
+				return err
+			}
+		}
+	}
+
+	return findings.WriteText(os.Stdout, d.Findings)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestExported(t *testing.T) {
+	tests := []struct {
+		name string
+		rels []string
+		file string
+		want bool
+	}{
+		{"whole repository", []string{"."}, "src/a/A.java", true},
+		{"under a directory", []string{"src"}, "src/a/A.java", true},
+		{"outside the directories", []string{"src/b"}, "src/a/A.java", false},
+		{"directory with a common prefix", []string{"src/a"}, "src/ab/A.java", false},
+		{"single file", []string{"src/a/A.java"}, "src/a/A.java", true},
+		{"other file", []string{"src/a/A.java"}, "src/b/B.java", false},
+		{"build file", []string{"src/a/A.java"}, "pom.xml", true},
+		{"not a source", []string{"."}, "README.md", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exported(tt.rels)(tt.file); got != tt.want {
+				t.Errorf("exported(%v)(%q) = %v, want %v", tt.rels, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff_unchanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"src/a/A.java": "package a;\n\nimport b.B;\nimport java.util.List;\nimport org.slf4j.Logger;\n\npublic class A {}\n",
+		"src/b/B.java": "package b;\n\nimport java.util.Map.Entry;\nimport static org.junit.Assert.assertEquals;\n\npublic class B {}\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed writing %s: %v", name, err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "commit"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = dir
+		if out, err := git.CombinedOutput(); err != nil {
+			t.Fatalf("git %v error = %v: %s", args, err, out)
+		}
+	}
+
+	tests := [][]string{
+		nil,
+		{"-jdk", "drop"},
+		{"-jdk", "collapse", "-third-party", "collapse"},
+		{"-third-party", "drop"},
+	}
+	for _, flags := range tests {
+		t.Run(fmt.Sprint(flags), func(t *testing.T) {
+			args := append([]string{"diff", "-quiet", "-format", "json", "-base", "HEAD"}, flags...)
+			code, out := runMainOutput(t, dir, append(args, "src")...)
+			if code != 0 {
+				t.Fatalf("exit code = %d, want 0", code)
+			}
+
+			var got graphDiff
+			if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatalf("failed parsing %q: %v", out, err)
+			}
+
+			if len(got.Added) != 0 || len(got.Removed) != 0 {
+				t.Errorf("added %v and removed %v, want no changes", got.Added, got.Removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/djavorszky/depser/dependency"
+)
+
+func runExport(args []string) {
+	c := newFlags("export", "Export the dependency graph.", "paths...", "dot", "json", "jsonl", "graphml", "gexf")
+	output := c.fs.String("o", "", "file to write to instead of stdout")
+	cluster := c.fs.String("cluster", "", "group nodes by package or module")
+	highlight := c.fs.Bool("highlight", false, "highlight cycles")
+	focus := c.fs.String("focus", "", "only export the classes around this one")
+	hops := c.fs.Int("hops", 1, "number of hops around the -focus class to export")
+
+	c.parse(args)
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	out := mustCreate(*output)
+
+	var err error
+
+	switch c.format {
+	case "dot":
+		err = dep.WriteDOT(out, dependency.DOTOptions{
+			Cluster:   *cluster,
+			Highlight: *highlight,
+			Focus:     *focus,
+			Hops:      *hops,
+		})
+	case "json":
+		err = dep.WriteJSON(out)
+	case "jsonl":
+		err = dep.WriteJSONLines(out)
+	case "graphml":
+		err = dep.WriteGraphML(out)
+	case "gexf":
+		err = dep.WriteGEXF(out)
+	}
+
+	if err != nil {
+		fail(exitFailure, "Failed exporting graph: %v", err)
+	}
+
+	mustClose(out)
+}
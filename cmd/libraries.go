@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/djavorszky/depser"
+	"github.com/djavorszky/depser/dependency"
+)
+
+// libraryReport is the result of the libraries command in JSON.
+type libraryReport struct {
+	Libraries  []dependency.LibraryUsage `json:"libraries"`
+	ByModule   []dependency.LibrarySet   `json:"byModule"`
+	ByPackage  []dependency.LibrarySet   `json:"byPackage"`
+	RarelyUsed []string                  `json:"rarelyUsed"`
+}
+
+func runLibraries(args []string) {
+	c := newFlags("libraries", "Report which third-party packages are used, where, and which of them are barely used.", "paths...", "text", "json", "csv")
+	rare := c.fs.Int("rare", 2, "report the libraries used by at most this many classes as candidates for removal")
+
+	c.parse(args)
+
+	if c.thirdParty != string(depser.ExternalKeep) {
+		c.usageError("The libraries are found by their classes, so -third-party must be keep")
+	}
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	usages := dep.Libraries()
+	rarelyUsed := dependency.RarelyUsed(usages, *rare)
+
+	var err error
+
+	switch c.format {
+	case "text":
+		err = writeLibrariesText(dep, usages, rarelyUsed, *rare)
+	case "json":
+		report := libraryReport{
+			Libraries:  usages,
+			ByModule:   dep.LibrariesByModule(),
+			ByPackage:  dep.LibrariesByPackage(),
+			RarelyUsed: []string{},
+		}
+
+		for _, u := range rarelyUsed {
+			report.RarelyUsed = append(report.RarelyUsed, u.Package)
+		}
+
+		err = writeJSON(report)
+	case "csv":
+		err = dependency.WriteLibrariesCSV(os.Stdout, usages, *rare)
+	}
+
+	if err != nil {
+		fail(exitFailure, "Failed writing libraries: %v", err)
+	}
+}
+
+func writeLibrariesText(dep *dependency.Dependency, usages, rarelyUsed []dependency.LibraryUsage, rare int) error {
+	if len(usages) == 0 {
+		_, err := fmt.Println("No third-party libraries are used.")
+		return err
+	}
+
+	if err := dependency.WriteLibrariesTable(os.Stdout, usages); err != nil {
+		return err
+	}
+
+	for _, section := range []struct {
+		title string
+		sets  []dependency.LibrarySet
+	}{
+		{"By module", dep.LibrariesByModule()},
+		{"By package", dep.LibrariesByPackage()},
+	} {
+		if len(section.sets) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n%s:\n", section.title)
+		for _, set := range section.sets {
+			fmt.Printf("  %s: %s\n", set.Name, strings.Join(set.Libraries, ", "))
+		}
+	}
+
+	if len(rarelyUsed) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nUsed by at most %d class(es), candidates for removal:\n", rare)
+	for _, u := range rarelyUsed {
+		fmt.Printf("  %s: %s\n", u.Package, strings.Join(u.Users, ", "))
+	}
+
+	return nil
+}
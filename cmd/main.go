@@ -1,85 +1,79 @@
 package main
 
 import (
-	"bufio"
-	"flag"
 	"fmt"
 	"log"
 	"os"
-	"time"
-
-	"github.com/djavorszky/depser"
+	"sort"
 )
 
-var sources []string
-
-func main() {
-	if len(os.Args) == 1 {
-		log.Println("Please specify one or more paths to check, or a filename with -f")
-		os.Exit(1)
-	}
-	epoch := time.Now()
-
-	fileName := flag.String("f", "none", "file to read for sources")
+// command is a depser subcommand.
+type command struct {
+	summary string
+	run     func(args []string)
+}
 
-	flag.Parse()
+var commands = map[string]command{
+	"scan":      {"scan the sources and print a summary, optionally saving the graph", runScan},
+	"diff":      {"report what the working tree changed compared to a git revision", runDiff},
+	"compare":   {"compare a saved graph to the current one", runCompare},
+	"cycles":    {"report dependency cycles and how to break them", runCycles},
+	"watch":     {"rescan the sources as they change and report cycles as they appear", runWatch},
+	"why":       {"show the shortest dependency chain from one class to another", runWhy},
+	"impact":    {"list the classes affected by a change of a class", runImpact},
+	"export":    {"export the dependency graph as dot, json, jsonl, graphml or gexf", runExport},
+	"check":     {"fail on dependency cycles and architecture rule violations", runCheck},
+	"baseline":  {"record the current cycles and violations as accepted", runBaseline},
+	"libraries": {"report the third-party packages used and where", runLibraries},
+	"metrics":   {"report package metrics", runMetrics},
+	"hotspots":  {"report the most central classes", runHotspots},
+	"report":    {"write a self-contained HTML report", runReport},
+	"serve":     {"serve the dependency graph over a local HTTP API", runServe},
+	"simulate":  {"show how a refactoring plan would change cycles and metrics", runSimulate},
+}
 
-	var err error
-	if *fileName == "none" {
-		sources = os.Args[1:]
-	} else {
-		sources, err = parseFile(*fileName)
-		if err != nil {
-			log.Printf("Failed parsing file: %v", err)
-			os.Exit(1)
-		}
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
 	}
 
-	log.Printf("Got %d source files\n", len(sources))
-	log.Println("Building dependencies")
-
-	start := time.Now()
-
-	dep, err := depser.BuildDependencies(true, sources)
-	if err != nil {
-		log.Printf("failed building dependencies: %v\n", err)
-		os.Exit(1)
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage()
+		return
 	}
 
-	log.Printf("Dependencies built in %s\n", time.Since(start))
-	log.Println("Checking cyclic dependencies")
-
-	start = time.Now()
-	cyclics, ok := dep.CheckCyclicDependencies()
+	cmd, ok := commands[name]
 	if !ok {
-		log.Printf("%d dependency cycle(s) detected:\n", len(cyclics))
-
-		for _, cycle := range cyclics {
-			log.Println(cycle)
-		}
-
+		log.Printf("Unknown command: %s\n\n", name)
+		usage()
+		os.Exit(exitUsage)
 	}
 
-	log.Printf("Cyclic dependency check done in %s\n", time.Since(start))
-	log.Printf("Whole process took %s\n", time.Since(epoch))
+	cmd.run(os.Args[2:])
 }
 
-func parseFile(fileName string) ([]string, error) {
-	var sources []string
-
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, fmt.Errorf("Failed opening file: %v", err)
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		sources = append(sources, scanner.Text())
-	}
+	sort.Strings(names)
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading from io.Reader: %v", err)
+	fmt.Fprintln(os.Stderr, "Usage: depser <command> [flags] [paths...]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
 	}
-
-	return sources, nil
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'depser <command> -help' for the flags of a command.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Exit codes:")
+	fmt.Fprintln(os.Stderr, "  1  cycles, rule violations or other findings were reported")
+	fmt.Fprintln(os.Stderr, "  2  invalid command, flags, arguments or configuration")
+	fmt.Fprintln(os.Stderr, "  3  the sources or the saved graph couldn't be read")
+	fmt.Fprintln(os.Stderr, "  4  any other failure, like failing to write the results")
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// runMain runs depser with args in dir in a separate process, as the
+// commands exit on their own, and returns its exit code.
+func runMain(t *testing.T, dir string, args ...string) int {
+	t.Helper()
+
+	code, _ := runMainOutput(t, dir, args...)
+
+	return code
+}
+
+// runMainOutput is runMain, also returning what depser wrote to stdout.
+func runMainOutput(t *testing.T, dir string, args ...string) (int, string) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestMainProcess", "--"}, args...)...)
+	cmd.Env = append(os.Environ(), "DEPSER_MAIN_PROCESS=1")
+	cmd.Dir = dir
+	cmd.Stdout = &out
+
+	err := cmd.Run()
+	if err == nil {
+		return 0, out.String()
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("failed running depser: %v", err)
+	}
+
+	// ExitError.ExitCode is too new for the Go versions supported.
+	return exitErr.Sys().(syscall.WaitStatus).ExitStatus(), out.String()
+}
+
+// TestMainProcess is not a test on its own, it runs depser for runMain.
+func TestMainProcess(t *testing.T) {
+	if os.Getenv("DEPSER_MAIN_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	os.Args = append([]string{"depser"}, args...)
+	main()
+	os.Exit(0)
+}
+
+func TestExitCodes(t *testing.T) {
+	root, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	for name, content := range map[string]string{
+		"clean/a/A.java":        "package a;\n\nimport b.B;\n\npublic class A {}\n",
+		"clean/b/B.java":        "package b;\n\npublic class B {}\n",
+		"cyclic/a/A.java":       "package a;\n\nimport b.B;\n\npublic class A {}\n",
+		"cyclic/b/B.java":       "package b;\n\nimport a.A;\n\npublic class B {}\n",
+		"malformed/a/A.java":    "package a;\n\nimport x\n\npublic class A {}\n",
+		"unterminated/a/A.java": "package a;\n\nimport b.B\n\npublic class A {}\n",
+		"ruled/depser.yaml":     "rules:\n  - type: forbid\n    from: a..\n    to: b..\n",
+	} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed writing %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		args []string
+		want int
+	}{
+		{"no cycles", "", []string{"cycles", "-quiet", filepath.Join(root, "clean")}, 0},
+		{"cycles", "", []string{"cycles", "-quiet", filepath.Join(root, "cyclic")}, exitFindings},
+		{"unknown command", "", []string{"nope"}, exitUsage},
+		{"unknown format", "", []string{"cycles", "-format", "nope", filepath.Join(root, "clean")}, exitUsage},
+		{"malformed import", "", []string{"cycles", "-quiet", filepath.Join(root, "malformed")}, exitParse},
+		{"import without semicolon", "", []string{"cycles", "-quiet", filepath.Join(root, "unterminated")}, exitParse},
+		{"missing graph", "", []string{"cycles", "-graph", filepath.Join(root, "missing.json")}, exitParse},
+		{"check without rules", "", []string{"check", "-quiet", filepath.Join(root, "clean")}, 0},
+		{"check with default rules", filepath.Join(root, "ruled"), []string{"check", "-quiet", filepath.Join(root, "clean")}, exitFindings},
+		{"check with rules disabled", filepath.Join(root, "ruled"), []string{"check", "-quiet", "-rules", "", filepath.Join(root, "clean")}, 0},
+		{"check with missing rules", "", []string{"check", "-rules", filepath.Join(root, "missing.yaml"), filepath.Join(root, "clean")}, exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runMain(t, tt.dir, tt.args...); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
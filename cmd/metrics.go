@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+func runMetrics(args []string) {
+	c := newFlags("metrics", "Report coupling, instability, abstractness and distance per package.", "paths...", "text", "csv", "json")
+
+	c.parse(args)
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	var err error
+
+	metrics := dep.PackageMetrics()
+
+	switch c.format {
+	case "text":
+		err = dependency.WritePackageMetricsTable(os.Stdout, metrics)
+	case "csv":
+		err = dependency.WritePackageMetricsCSV(os.Stdout, metrics)
+	case "json":
+		err = dependency.WritePackageMetricsJSON(os.Stdout, metrics)
+	}
+
+	if err != nil {
+		fail(exitFailure, "Failed writing metrics: %v", err)
+	}
+}
+
+func runHotspots(args []string) {
+	c := newFlags("hotspots", "Report the classes most central to the dependency graph.", "paths...", "text", "csv", "json")
+	top := c.fs.Int("top", 20, "number of classes to report, 0 for all")
+	by := c.fs.String("by", "betweenness", "sort by: in, out, reach, betweenness or pagerank")
+
+	c.parse(args)
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	metrics, err := dependency.TopNodeMetrics(dep.NodeMetrics(), *by, *top)
+	if err != nil {
+		c.usageError("Failed sorting hotspots: %v", err)
+	}
+
+	switch c.format {
+	case "text":
+		err = dependency.WriteNodeMetricsTable(os.Stdout, metrics)
+	case "csv":
+		err = dependency.WriteNodeMetricsCSV(os.Stdout, metrics)
+	case "json":
+		err = dependency.WriteNodeMetricsJSON(os.Stdout, metrics)
+	}
+
+	if err != nil {
+		fail(exitFailure, "Failed writing hotspots: %v", err)
+	}
+}
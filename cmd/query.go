@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func runWhy(args []string) {
+	c := newFlags("why", "Show the shortest chain of imports from one class to another.", "<from> <to> paths...", "text", "json", "csv")
+
+	c.parse(args)
+
+	if c.fs.NArg() < 2 {
+		c.usageError("Please specify the classes to find the path between")
+	}
+
+	from, to := c.fs.Arg(0), c.fs.Arg(1)
+
+	dep := c.mustLoadGraph(c.fs.Args()[2:])
+
+	path := dep.ShortestPath(from, to)
+	if path == nil {
+		progress.Printf("%s does not depend on %s\n", from, to)
+		path = []string{}
+	}
+
+	switch c.format {
+	case "text":
+		if len(path) != 0 {
+			_, err := fmt.Println(strings.Join(path, " -> "))
+			mustWrite(err)
+		}
+	case "json":
+		mustWrite(writeJSON(path))
+	case "csv":
+		rows := make([][]string, 0, len(path))
+		for i, class := range path {
+			rows = append(rows, []string{strconv.Itoa(i), class})
+		}
+
+		mustWrite(writeCSV([]string{"step", "class"}, rows))
+	}
+}
+
+// impacted is a class affected by a change, and how many dependencies
+// away it is.
+type impacted struct {
+	Class    string `json:"class"`
+	Distance int    `json:"distance"`
+}
+
+func runImpact(args []string) {
+	c := newFlags("impact", "List the classes that transitively depend on a class, nearest first.", "<class> paths...", "text", "json", "csv")
+
+	c.parse(args)
+
+	if c.fs.NArg() < 1 {
+		c.usageError("Please specify the class to assess the impact of changing")
+	}
+
+	class := c.fs.Arg(0)
+
+	dep := c.mustLoadGraph(c.fs.Args()[1:])
+
+	impact := make([]impacted, 0)
+	for dependent, distance := range dep.Impact(class) {
+		impact = append(impact, impacted{dependent, distance})
+	}
+
+	sort.Slice(impact, func(i, j int) bool {
+		if impact[i].Distance != impact[j].Distance {
+			return impact[i].Distance < impact[j].Distance
+		}
+
+		return impact[i].Class < impact[j].Class
+	})
+
+	progress.Printf("%d class(es) affected by a change of %s\n", len(impact), class)
+
+	switch c.format {
+	case "text":
+		for _, i := range impact {
+			_, err := fmt.Printf("%d\t%s\n", i.Distance, i.Class)
+			mustWrite(err)
+		}
+	case "json":
+		mustWrite(writeJSON(impact))
+	case "csv":
+		rows := make([][]string, 0, len(impact))
+		for _, i := range impact {
+			rows = append(rows, []string{i.Class, strconv.Itoa(i.Distance)})
+		}
+
+		mustWrite(writeCSV([]string{"class", "distance"}, rows))
+	}
+}
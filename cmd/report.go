@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/djavorszky/depser/server"
+)
+
+func runReport(args []string) {
+	c := newFlags("report", "Write a self-contained HTML report of the dependency graph.", "paths...")
+	html := c.fs.String("html", "", "file to write the HTML report to")
+
+	c.parse(args)
+
+	if *html == "" {
+		c.usageError("Please specify the report file with -html")
+	}
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	out := mustCreate(*html)
+
+	if err := dep.WriteHTML(out); err != nil {
+		fail(exitFailure, "Failed writing report: %v", err)
+	}
+
+	mustClose(out)
+
+	progress.Printf("Report written to %s\n", *html)
+}
+
+func runServe(args []string) {
+	c := newFlags("serve", "Serve the dependency graph and its HTML report over HTTP.", "paths...")
+	addr := c.fs.String("addr", "localhost:8080", "address to listen on")
+
+	c.parse(args)
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	srv, err := server.New(dep)
+	if err != nil {
+		fail(exitFailure, "Failed preparing server: %v", err)
+	}
+
+	progress.Printf("Serving on http://%s\n", *addr)
+
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		fail(exitFailure, "Server stopped: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+// summary is the result of the scan command.
+type summary struct {
+	Classes      int `json:"classes"`
+	Internal     int `json:"internal"`
+	JDK          int `json:"jdk"`
+	ThirdParty   int `json:"thirdParty"`
+	Dependencies int `json:"dependencies"`
+	Cycles       int `json:"cycles"`
+}
+
+func runScan(args []string) {
+	c := newFlags("scan", "Scan the sources and print a summary of the dependency graph.", "paths...", "text", "json", "csv")
+	output := c.fs.String("o", "", "save the graph to this file, as jsonl if it ends with .jsonl and as json otherwise")
+
+	c.parse(args)
+
+	dep := c.mustLoadGraph(c.fs.Args())
+
+	if *output != "" {
+		mustSaveGraph(dep, *output)
+	}
+
+	s := summary{Cycles: len(dep.StronglyConnectedComponents())}
+	for _, class := range dep.Classes() {
+		s.Classes++
+
+		switch dep.OriginOf(class) {
+		case dependency.Internal:
+			s.Internal++
+		case dependency.JDK:
+			s.JDK++
+		case dependency.ThirdParty:
+			s.ThirdParty++
+		}
+
+		s.Dependencies += len(dep.Dependencies(class))
+	}
+
+	switch c.format {
+	case "text":
+		_, err := fmt.Printf("%d class(es) (%d internal, %d jdk, %d third-party), %d dependencies, %d dependency cycle(s)\n",
+			s.Classes, s.Internal, s.JDK, s.ThirdParty, s.Dependencies, s.Cycles)
+		mustWrite(err)
+	case "json":
+		mustWrite(writeJSON(s))
+	case "csv":
+		mustWrite(writeCSV([]string{"classes", "internal", "jdk", "third-party", "dependencies", "cycles"}, [][]string{{
+			strconv.Itoa(s.Classes),
+			strconv.Itoa(s.Internal),
+			strconv.Itoa(s.JDK),
+			strconv.Itoa(s.ThirdParty),
+			strconv.Itoa(s.Dependencies),
+			strconv.Itoa(s.Cycles),
+		}}))
+	}
+}
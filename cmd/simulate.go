@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/plan"
+)
+
+func runSimulate(args []string) {
+	c := newFlags("simulate", "Apply the refactoring steps of a plan to the graph and report how its cycles and metrics would change.", "<plan.yaml> paths...", "text", "json")
+	output := c.fs.String("o", "", "save the resulting graph to this file, as jsonl if it ends with .jsonl and as json otherwise")
+
+	c.parse(args)
+
+	if c.fs.NArg() < 1 {
+		c.usageError("Please specify the plan to simulate")
+	}
+
+	p, err := plan.Load(c.fs.Arg(0))
+	if err != nil {
+		fail(exitUsage, "Failed loading plan: %v", err)
+	}
+
+	before := c.mustLoadGraph(c.fs.Args()[1:])
+
+	after := before.Clone()
+	if err := p.Apply(after); err != nil {
+		fail(exitUsage, "Failed applying plan: %v", err)
+	}
+
+	for _, s := range p.Steps {
+		progress.Printf("Applied %v\n", s)
+	}
+
+	if *output != "" {
+		mustSaveGraph(after, *output)
+	}
+
+	diff := dependency.Compare(before, after)
+
+	switch c.format {
+	case "text":
+		err = dependency.WriteGraphDiffText(os.Stdout, diff)
+	case "json":
+		err = dependency.WriteGraphDiffJSON(os.Stdout, diff)
+	}
+
+	mustWrite(err)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/djavorszky/depser"
+)
+
+func runWatch(args []string) {
+	c := newFlags("watch", "Rescan the sources whenever they change, and report the dependency cycles introduced and resolved.", "paths...")
+	interval := c.fs.Duration("interval", time.Second, "how often to check the sources for changes")
+
+	c.parse(args)
+
+	if c.graphFile != "" {
+		c.usageError("A saved graph can't be watched")
+	}
+
+	if *interval <= 0 {
+		c.usageError("The interval must be positive")
+	}
+
+	roots := c.mustRoots(c.fs.Args())
+
+	options := c.mustOptions()
+
+	tree, err := depser.NewTree(roots, options)
+	if err != nil {
+		fail(exitParse, "Failed building dependencies: %v", err)
+	}
+
+	saveCache(options.Cache)
+
+	cycles := tree.Graph().StronglyConnectedComponents()
+	for _, scc := range cycles {
+		printWatched("Dependency cycle", scc)
+	}
+
+	progress.Printf("Watching %d class(es) in %d dependency cycle(s), press Ctrl+C to stop\n", len(tree.Graph().Classes()), len(cycles))
+
+	for range time.Tick(*interval) {
+		changes, err := tree.Changes()
+		if err != nil {
+			progress.Printf("Failed looking for changes: %v\n", err)
+			continue
+		}
+
+		if changes.Empty() {
+			continue
+		}
+
+		if err := tree.Apply(changes); err != nil {
+			// Files may be caught in the middle of being saved, so
+			// keep watching and try again.
+			progress.Printf("Failed updating dependencies: %v\n", err)
+			continue
+		}
+
+		saveCache(options.Cache)
+
+		before := cycles
+		cycles = tree.Graph().StronglyConnectedComponents()
+
+		reportCycleChanges(before, cycles)
+
+		progress.Printf("%d file(s) changed, %d dependency cycle(s) now\n",
+			len(changes.Modified)+len(changes.Deleted), len(cycles))
+	}
+}
+
+// reportCycleChanges prints the cycles that appeared, changed or went away
+// between two sets of strongly connected components. A cycle changed if it
+// shares classes with one from before.
+func reportCycleChanges(before, after [][]string) {
+	known := make(map[string]struct{}, len(before))
+	for _, scc := range before {
+		known[strings.Join(scc, ",")] = struct{}{}
+	}
+
+	for _, scc := range after {
+		if _, ok := known[strings.Join(scc, ",")]; ok {
+			continue
+		}
+
+		title := "New dependency cycle"
+		if overlaps(scc, before) {
+			title = "Changed dependency cycle"
+		}
+
+		printWatched(title, scc)
+	}
+
+	for _, scc := range before {
+		if !overlaps(scc, after) {
+			printWatched("Resolved dependency cycle", scc)
+		}
+	}
+}
+
+// overlaps returns true if any class of scc is in any of the sccs.
+func overlaps(scc []string, sccs [][]string) bool {
+	for _, other := range sccs {
+		for _, class := range other {
+			if contains(scc, class) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// saveCache saves the cache if one was given with -cache.
+func saveCache(cache *depser.Cache) {
+	if cache == nil {
+		return
+	}
+
+	if err := cache.Save(); err != nil {
+		progress.Printf("Failed saving cache: %v\n", err)
+	}
+}
+
+func printWatched(title string, scc []string) {
+	_, err := fmt.Printf("%s %s between %d class(es): %s\n",
+		time.Now().Format("15:04:05"), title, len(scc), strings.Join(scc, ", "))
+	mustWrite(err)
+}
@@ -0,0 +1,316 @@
+package dependency
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Edge is a dependency between two classes.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// GraphDiff holds what changed between two graphs. Cycles are new or
+// resolved as decided by CompareCycles.
+type GraphDiff struct {
+	Before GraphSummary `json:"before"`
+	After  GraphSummary `json:"after"`
+
+	AddedClasses        []string       `json:"addedClasses"`
+	RemovedClasses      []string       `json:"removedClasses"`
+	AddedDependencies   []Edge         `json:"addedDependencies"`
+	RemovedDependencies []Edge         `json:"removedDependencies"`
+	NewCycles           [][]string     `json:"newCycles"`
+	ResolvedCycles      [][]string     `json:"resolvedCycles"`
+	Packages            []PackageDelta `json:"packages"`
+}
+
+// GraphSummary holds the overall figures of a graph, to tell whether it's
+// getting better or worse. MeanDistance is the average distance from the
+// main sequence of the packages.
+type GraphSummary struct {
+	Classes       int     `json:"classes"`
+	Dependencies  int     `json:"dependencies"`
+	Cycles        int     `json:"cycles"`
+	CyclicClasses int     `json:"cyclicClasses"`
+	MeanDistance  float64 `json:"meanDistance"`
+}
+
+// PackageDelta holds the metrics of a package that changed. Before is nil
+// for new packages, After is nil for removed ones.
+type PackageDelta struct {
+	Package string          `json:"package"`
+	Before  *PackageMetrics `json:"before"`
+	After   *PackageMetrics `json:"after"`
+}
+
+// Compare reports what changed from before to after: the classes and
+// dependencies added and removed, the cycles introduced and resolved, and
+// the packages whose metrics changed.
+func Compare(before, after *Dependency) GraphDiff {
+	diff := GraphDiff{
+		AddedClasses:        make([]string, 0),
+		RemovedClasses:      make([]string, 0),
+		AddedDependencies:   subtractEdges(after, before),
+		RemovedDependencies: subtractEdges(before, after),
+		NewCycles:           make([][]string, 0),
+		ResolvedCycles:      make([][]string, 0),
+		Packages:            make([]PackageDelta, 0),
+	}
+
+	beforeClasses, afterClasses := before.Classes(), after.Classes()
+	diff.AddedClasses = append(diff.AddedClasses, subtract(afterClasses, beforeClasses)...)
+	diff.RemovedClasses = append(diff.RemovedClasses, subtract(beforeClasses, afterClasses)...)
+
+	beforeSCCs, afterSCCs := before.StronglyConnectedComponents(), after.StronglyConnectedComponents()
+	added, resolved := CompareCycles(before.cycleEdges(beforeSCCs), after.cycleEdges(afterSCCs))
+	for _, i := range added {
+		diff.NewCycles = append(diff.NewCycles, afterSCCs[i])
+	}
+	for _, i := range resolved {
+		diff.ResolvedCycles = append(diff.ResolvedCycles, beforeSCCs[i])
+	}
+
+	beforeMetrics, afterMetrics := before.PackageMetrics(), after.PackageMetrics()
+	diff.Packages = packageDeltas(beforeMetrics, afterMetrics)
+
+	diff.Before = summarize(beforeClasses, beforeSCCs, beforeMetrics, before)
+	diff.After = summarize(afterClasses, afterSCCs, afterMetrics, after)
+
+	return diff
+}
+
+// subtract returns the strings of the sorted a that are not in b.
+func subtract(a, b []string) []string {
+	in := make(map[string]struct{}, len(b))
+	for _, s := range b {
+		in[s] = struct{}{}
+	}
+
+	var out []string
+	for _, s := range a {
+		if _, ok := in[s]; !ok {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+// subtractEdges returns the dependencies of d that other doesn't have,
+// ordered by class.
+func subtractEdges(d, other *Dependency) []Edge {
+	edges := make([]Edge, 0)
+
+	for _, from := range d.Classes() {
+		for _, to := range subtract(d.Dependencies(from), other.Dependencies(from)) {
+			edges = append(edges, Edge{from, to})
+		}
+	}
+
+	return edges
+}
+
+// cycleEdges returns the unsuppressed dependencies within each of the
+// components.
+func (d *Dependency) cycleEdges(sccs [][]string) [][]Edge {
+	cycles := make([][]Edge, 0, len(sccs))
+
+	for _, scc := range sccs {
+		members := make(map[string]struct{}, len(scc))
+		for _, class := range scc {
+			members[class] = struct{}{}
+		}
+
+		var edges []Edge
+		for _, from := range scc {
+			for _, to := range d.activeDependencies(from) {
+				if _, ok := members[to]; ok {
+					edges = append(edges, Edge{from, to})
+				}
+			}
+		}
+
+		cycles = append(cycles, edges)
+	}
+
+	return cycles
+}
+
+// CompareCycles compares two sets of cycles, each given by the
+// dependencies within it, and returns the indexes of the new cycles of
+// after and of the resolved cycles of before.
+//
+// A cycle is new if it contains a dependency that wasn't part of any cycle
+// before, and resolved if none of its dependencies are part of a cycle
+// anymore. A cycle that only grew or shrank is neither.
+func CompareCycles(before, after [][]Edge) (added, resolved []int) {
+	known, remaining := edgeSet(before), edgeSet(after)
+
+	for i, edges := range after {
+		for _, e := range edges {
+			if _, ok := known[e]; !ok {
+				added = append(added, i)
+				break
+			}
+		}
+	}
+
+	for i, edges := range before {
+		isResolved := true
+		for _, e := range edges {
+			if _, ok := remaining[e]; ok {
+				isResolved = false
+				break
+			}
+		}
+
+		if isResolved {
+			resolved = append(resolved, i)
+		}
+	}
+
+	return added, resolved
+}
+
+func edgeSet(cycles [][]Edge) map[Edge]struct{} {
+	edges := make(map[Edge]struct{})
+	for _, c := range cycles {
+		for _, e := range c {
+			edges[e] = struct{}{}
+		}
+	}
+
+	return edges
+}
+
+// packageDeltas pairs up the metrics of the packages, keeping the ones
+// that changed. Both slices are sorted by package.
+func packageDeltas(before, after []PackageMetrics) []PackageDelta {
+	deltas := make([]PackageDelta, 0)
+
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case j == len(after) || (i < len(before) && before[i].Package < after[j].Package):
+			deltas = append(deltas, PackageDelta{Package: before[i].Package, Before: &before[i]})
+			i++
+		case i == len(before) || after[j].Package < before[i].Package:
+			deltas = append(deltas, PackageDelta{Package: after[j].Package, After: &after[j]})
+			j++
+		default:
+			if before[i] != after[j] {
+				deltas = append(deltas, PackageDelta{Package: after[j].Package, Before: &before[i], After: &after[j]})
+			}
+
+			i++
+			j++
+		}
+	}
+
+	return deltas
+}
+
+func summarize(classes []string, sccs [][]string, metrics []PackageMetrics, d *Dependency) GraphSummary {
+	s := GraphSummary{Classes: len(classes), Cycles: len(sccs)}
+
+	for _, class := range classes {
+		s.Dependencies += len(d.Dependencies(class))
+	}
+
+	for _, scc := range sccs {
+		s.CyclicClasses += len(scc)
+	}
+
+	if len(metrics) != 0 {
+		for _, m := range metrics {
+			s.MeanDistance += m.Distance
+		}
+
+		s.MeanDistance /= float64(len(metrics))
+	}
+
+	return s
+}
+
+// WriteGraphDiffText writes the diff in a human readable form, starting
+// with how the overall figures changed.
+func WriteGraphDiffText(w io.Writer, diff GraphDiff) error {
+	bw := bufio.NewWriter(w)
+
+	b, a := diff.Before, diff.After
+	fmt.Fprintf(bw, "Classes:        %d -> %d (%+d)\n", b.Classes, a.Classes, a.Classes-b.Classes)
+	fmt.Fprintf(bw, "Dependencies:   %d -> %d (%+d)\n", b.Dependencies, a.Dependencies, a.Dependencies-b.Dependencies)
+	fmt.Fprintf(bw, "Cycles:         %d -> %d (%+d)\n", b.Cycles, a.Cycles, a.Cycles-b.Cycles)
+	fmt.Fprintf(bw, "Cyclic classes: %d -> %d (%+d)\n", b.CyclicClasses, a.CyclicClasses, a.CyclicClasses-b.CyclicClasses)
+	fmt.Fprintf(bw, "Mean distance:  %.2f -> %.2f (%+.2f)\n", b.MeanDistance, a.MeanDistance, a.MeanDistance-b.MeanDistance)
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+
+		fmt.Fprintf(bw, "\n%s:\n", title)
+		for _, item := range items {
+			fmt.Fprintf(bw, "  %s\n", item)
+		}
+	}
+
+	writeList("Added classes", diff.AddedClasses)
+	writeList("Removed classes", diff.RemovedClasses)
+	writeList("Added dependencies", edgeStrings(diff.AddedDependencies))
+	writeList("Removed dependencies", edgeStrings(diff.RemovedDependencies))
+	writeList("New cycles", cycleStrings(diff.NewCycles))
+	writeList("Resolved cycles", cycleStrings(diff.ResolvedCycles))
+
+	if len(diff.Packages) != 0 {
+		fmt.Fprintln(bw, "\nPackages:")
+
+		for _, p := range diff.Packages {
+			switch {
+			case p.Before == nil:
+				fmt.Fprintf(bw, "  %s (new): I %.2f, A %.2f, D %.2f\n", p.Package, p.After.Instability, p.After.Abstractness, p.After.Distance)
+			case p.After == nil:
+				fmt.Fprintf(bw, "  %s (removed)\n", p.Package)
+			default:
+				fmt.Fprintf(bw, "  %s: Ca %+d, Ce %+d, I %+.2f, A %+.2f, D %+.2f\n", p.Package,
+					p.After.Ca-p.Before.Ca, p.After.Ce-p.Before.Ce,
+					p.After.Instability-p.Before.Instability,
+					p.After.Abstractness-p.Before.Abstractness,
+					p.After.Distance-p.Before.Distance)
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+func edgeStrings(edges []Edge) []string {
+	s := make([]string, 0, len(edges))
+	for _, e := range edges {
+		s = append(s, e.From+" -> "+e.To)
+	}
+
+	return s
+}
+
+func cycleStrings(cycles [][]string) []string {
+	s := make([]string, 0, len(cycles))
+	for _, c := range cycles {
+		s = append(s, strings.Join(c, ", "))
+	}
+
+	return s
+}
+
+// WriteGraphDiffJSON writes the diff as indented JSON.
+func WriteGraphDiffJSON(w io.Writer, diff GraphDiff) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(diff)
+}
@@ -0,0 +1,163 @@
+package dependency
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	before := NewWithCycles(true)
+	before.Add("a.A", "b.B")
+	before.Add("b.B", "a.A")
+	before.Add("c.C", "d.D")
+	before.Add("d.D", "c.C")
+	before.Add("e.E", "gone.G")
+
+	after := NewWithCycles(true)
+	after.Add("a.A", "b.B")
+	after.Add("b.B", "a.A")
+	after.Add("c.C", "d.D")
+	after.Add("e.E", "f.F")
+	after.Add("f.F", "e.E")
+
+	diff := Compare(before, after)
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"added classes", diff.AddedClasses, []string{"f.F"}},
+		{"removed classes", diff.RemovedClasses, []string{"gone.G"}},
+		{"added dependencies", diff.AddedDependencies, []Edge{{"e.E", "f.F"}, {"f.F", "e.E"}}},
+		{"removed dependencies", diff.RemovedDependencies, []Edge{{"d.D", "c.C"}, {"e.E", "gone.G"}}},
+		{"new cycles", diff.NewCycles, [][]string{{"e.E", "f.F"}}},
+		{"resolved cycles", diff.ResolvedCycles, [][]string{{"c.C", "d.D"}}},
+		{"cycles before", diff.Before.Cycles, 2},
+		{"cycles after", diff.After.Cycles, 2},
+		{"dependencies after", diff.After.Dependencies, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("Compare() %s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	changed := make(map[string]bool)
+	for _, p := range diff.Packages {
+		changed[p.Package] = true
+
+		if p.Before != nil && p.After != nil && *p.Before == *p.After {
+			t.Errorf("package %s didn't change, want it left out", p.Package)
+		}
+	}
+
+	for _, pkg := range []string{"d", "f", "gone"} {
+		if !changed[pkg] {
+			t.Errorf("package %s is missing from %v", pkg, diff.Packages)
+		}
+	}
+
+	if changed["a"] {
+		t.Errorf("package a didn't change, want it left out")
+	}
+}
+
+func TestCompare_same(t *testing.T) {
+	d := testGraph()
+
+	diff := Compare(d, d)
+
+	if len(diff.AddedDependencies) != 0 || len(diff.RemovedDependencies) != 0 ||
+		len(diff.NewCycles) != 0 || len(diff.ResolvedCycles) != 0 || len(diff.Packages) != 0 {
+		t.Errorf("Compare() of the same graph = %+v, want no changes", diff)
+	}
+
+	if diff.Before != diff.After {
+		t.Errorf("Compare() summaries differ: %+v != %+v", diff.Before, diff.After)
+	}
+}
+
+func TestCompareCycles(t *testing.T) {
+	ab := []Edge{{"a.A", "b.B"}, {"b.B", "a.A"}}
+	abc := []Edge{{"a.A", "b.B"}, {"b.B", "c.C"}, {"c.C", "a.A"}}
+	cd := []Edge{{"c.C", "d.D"}, {"d.D", "c.C"}}
+
+	tests := []struct {
+		name         string
+		before       [][]Edge
+		after        [][]Edge
+		wantAdded    []int
+		wantResolved []int
+	}{
+		{"unchanged", [][]Edge{ab}, [][]Edge{ab}, nil, nil},
+		{"new", [][]Edge{ab}, [][]Edge{ab, cd}, []int{1}, nil},
+		{"resolved", [][]Edge{ab, cd}, [][]Edge{cd}, nil, []int{0}},
+		{"grew", [][]Edge{ab}, [][]Edge{append(ab, abc[1:]...)}, []int{0}, nil},
+		{"shrank", [][]Edge{append(ab, abc[1:]...)}, [][]Edge{ab}, nil, nil},
+		{"replaced", [][]Edge{ab}, [][]Edge{abc}, []int{0}, nil},
+		{"none", nil, nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, resolved := CompareCycles(tt.before, tt.after)
+			if !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("CompareCycles() added = %v, want %v", added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(resolved, tt.wantResolved) {
+				t.Errorf("CompareCycles() resolved = %v, want %v", resolved, tt.wantResolved)
+			}
+		})
+	}
+}
+
+func TestWriteGraphDiffText(t *testing.T) {
+	before := NewWithCycles(true)
+	before.Add("a.A", "b.B")
+
+	after := NewWithCycles(true)
+	after.Add("a.A", "b.B")
+	after.Add("b.B", "a.A")
+
+	var buf bytes.Buffer
+	if err := WriteGraphDiffText(&buf, Compare(before, after)); err != nil {
+		t.Fatalf("WriteGraphDiffText() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Cycles:         0 -> 1 (+1)\n",
+		"Added dependencies:\n  b.B -> a.A\n",
+		"New cycles:\n  a.A, b.B\n",
+		"  b: Ca +0, Ce +1,",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteGraphDiffText() = %s, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestWriteGraphDiffJSON(t *testing.T) {
+	before := NewWithCycles(true)
+	before.Add("a.A", "b.B")
+
+	diff := Compare(before, NewWithCycles(true))
+
+	var buf bytes.Buffer
+	if err := WriteGraphDiffJSON(&buf, diff); err != nil {
+		t.Fatalf("WriteGraphDiffJSON() error = %v", err)
+	}
+
+	var got GraphDiff
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteGraphDiffJSON() wrote invalid JSON: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, diff) {
+		t.Errorf("WriteGraphDiffJSON() round trip = %+v, want %+v", got, diff)
+	}
+}
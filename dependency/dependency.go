@@ -13,14 +13,24 @@ import (
 type Dependency struct {
 	depRW        *sync.RWMutex
 	deps         map[string][]string
+	sites        map[edge]int
+	suppressed   map[edge]string
+	provenance   map[edge][]Provenance
 	visRW        *sync.RWMutex
 	visibilities map[string][]string
+	nodeRW       *sync.RWMutex
+	nodes        map[string]Node
 	allowCycles  bool
 
 	knownCyclers sync.Map
 	cyclicDeps   sync.Map
 }
 
+// edge is a single depender -> dependent relation.
+type edge struct {
+	from, to string
+}
+
 // New returns a ready-to-use Dependency struct. By default it is
 // set not to allow dependency cycles. See NewWithCycles if you
 // want to enable it
@@ -32,21 +42,65 @@ func New() *Dependency {
 // decide whether to allow dependency cycles or not.
 func NewWithCycles(allowCycles bool) *Dependency {
 	var (
-		dep sync.RWMutex
-		vis sync.RWMutex
+		dep  sync.RWMutex
+		vis  sync.RWMutex
+		node sync.RWMutex
 	)
 
 	dependency := Dependency{
 		allowCycles:  allowCycles,
 		deps:         make(map[string][]string),
+		sites:        make(map[edge]int),
+		suppressed:   make(map[edge]string),
+		provenance:   make(map[edge][]Provenance),
 		visibilities: make(map[string][]string),
+		nodes:        make(map[string]Node),
 		depRW:        &dep,
 		visRW:        &vis,
+		nodeRW:       &node,
 	}
 
 	return &dependency
 }
 
+// Clone returns a copy of the graph that can be changed independently,
+// e.g. to compare it with the original afterwards.
+func (d *Dependency) Clone() *Dependency {
+	c := NewWithCycles(d.allowCycles)
+
+	d.depRW.RLock()
+	for depender, dependents := range d.deps {
+		c.deps[depender] = append([]string(nil), dependents...)
+	}
+
+	for e, n := range d.sites {
+		c.sites[e] = n
+	}
+
+	for e, reason := range d.suppressed {
+		c.suppressed[e] = reason
+	}
+
+	for e, records := range d.provenance {
+		c.provenance[e] = append([]Provenance(nil), records...)
+	}
+	d.depRW.RUnlock()
+
+	d.visRW.RLock()
+	for stalked, stalkers := range d.visibilities {
+		c.visibilities[stalked] = append([]string(nil), stalkers...)
+	}
+	d.visRW.RUnlock()
+
+	d.nodeRW.RLock()
+	for name, n := range d.nodes {
+		c.nodes[name] = n
+	}
+	d.nodeRW.RUnlock()
+
+	return c
+}
+
 // Add adds a new dependency to the depender, as well as set the
 // corresponding visibility.
 //
@@ -72,6 +126,118 @@ func (d *Dependency) Add(depender, dependent string) error {
 	return d.checkCycles(depender)
 }
 
+// Remove removes the dependency from depender to dependent, along with
+// its visibility, import sites and suppression.
+func (d *Dependency) Remove(depender, dependent string) error {
+	if depender == "" || dependent == "" {
+		return fmt.Errorf("empty dependant or dependee")
+	}
+
+	d.depRW.Lock()
+	removed := d.mustRemoveDependency(depender, dependent)
+	d.depRW.Unlock()
+
+	if !removed {
+		return fmt.Errorf("%s does not depend on %s", depender, dependent)
+	}
+
+	d.visRW.Lock()
+	d.mustRemoveVisibility(dependent, depender)
+	d.visRW.Unlock()
+
+	d.resetCycles()
+
+	return nil
+}
+
+// RemoveNode removes the class from the graph: the dependencies from and
+// to it, and the details set with SetNode.
+func (d *Dependency) RemoveNode(class string) error {
+	if class == "" {
+		return fmt.Errorf("empty class")
+	}
+
+	d.nodeRW.Lock()
+	_, found := d.nodes[class]
+	delete(d.nodes, class)
+	d.nodeRW.Unlock()
+
+	d.depRW.Lock()
+	d.visRW.Lock()
+
+	for _, dependent := range append([]string(nil), d.deps[class]...) {
+		d.mustRemoveDependency(class, dependent)
+		d.mustRemoveVisibility(dependent, class)
+		found = true
+	}
+
+	for _, depender := range append([]string(nil), d.visibilities[class]...) {
+		d.mustRemoveDependency(depender, class)
+		d.mustRemoveVisibility(class, depender)
+		found = true
+	}
+
+	d.visRW.Unlock()
+	d.depRW.Unlock()
+
+	if !found {
+		return fmt.Errorf("unknown class: %s", class)
+	}
+
+	d.resetCycles()
+
+	return nil
+}
+
+// SetDependencies replaces the dependencies of depender with dependents,
+// e.g. when the file declaring it changed. Dependents may repeat, each
+// occurrence counting as an import site like with Add. The suppressions
+// of the replaced dependencies are dropped.
+func (d *Dependency) SetDependencies(depender string, dependents []string) error {
+	if depender == "" {
+		return fmt.Errorf("empty dependant")
+	}
+
+	for _, dependent := range dependents {
+		if dependent == "" {
+			return fmt.Errorf("empty dependee")
+		}
+	}
+
+	d.depRW.Lock()
+	d.visRW.Lock()
+
+	for _, dependent := range append([]string(nil), d.deps[depender]...) {
+		d.mustRemoveDependency(depender, dependent)
+		d.mustRemoveVisibility(dependent, depender)
+	}
+
+	for _, dependent := range dependents {
+		d.mustAddDependency(depender, dependent)
+		d.mustAddVisibility(dependent, depender)
+	}
+
+	d.visRW.Unlock()
+	d.depRW.Unlock()
+
+	d.resetCycles()
+
+	if d.allowCycles {
+		return nil
+	}
+
+	return d.checkCycles(depender)
+}
+
+// ImportSites returns how many times the dependency from depender to
+// dependent was added, i.e. how many import statements back it.
+func (d *Dependency) ImportSites(depender, dependent string) int {
+	d.depRW.RLock()
+	defer d.depRW.RUnlock()
+
+	return d.sites[edge{depender, dependent}]
+}
+
 // CheckCyclicDependencies checks to see if there are any cyclic dependencies.
 // If there are, it will return them as a slice of strings.
 //
@@ -127,9 +293,7 @@ func (d *Dependency) checkCyclesAsync(depender string, wg *sync.WaitGroup) {
 }
 
 func (d *Dependency) check(route, depender string, seen map[string]struct{}) error {
-	d.depRW.RLock()
-	dependents := d.deps[depender]
-	d.depRW.RUnlock()
+	dependents := d.activeDependencies(depender)
 
 	sort.Strings(dependents)
 	for _, dep := range dependents {
@@ -170,6 +334,8 @@ func (d *Dependency) mustAddDependency(depender, dependent string) {
 		panic("empty dependent or dependee")
 	}
 
+	d.sites[edge{depender, dependent}]++
+
 	dependees := d.deps[depender]
 
 	// Check if dependency already exists
@@ -184,6 +350,23 @@ func (d *Dependency) mustAddDependency(depender, dependent string) {
 	d.deps[depender] = dependees
 }
 
+// mustRemoveDependency is not concurrent-safe. It returns whether the
+// dependency existed.
+func (d *Dependency) mustRemoveDependency(depender, dependent string) bool {
+	delete(d.sites, edge{depender, dependent})
+	delete(d.suppressed, edge{depender, dependent})
+	delete(d.provenance, edge{depender, dependent})
+
+	dependees, removed := removeString(d.deps[depender], dependent)
+	if len(dependees) == 0 {
+		delete(d.deps, depender)
+	} else {
+		d.deps[depender] = dependees
+	}
+
+	return removed
+}
+
 // mustAddVisibility is not concurrent-safe.
 //
 // If A depends on B, then A is the depender, B is the dependent.
@@ -208,6 +391,39 @@ func (d *Dependency) mustAddVisibility(stalked, stalker string) {
 	d.visibilities[stalked] = stalkers
 }
 
+// mustRemoveVisibility is not concurrent-safe.
+func (d *Dependency) mustRemoveVisibility(stalked, stalker string) {
+	stalkers, _ := removeString(d.visibilities[stalked], stalker)
+	if len(stalkers) == 0 {
+		delete(d.visibilities, stalked)
+	} else {
+		d.visibilities[stalked] = stalkers
+	}
+}
+
+// removeString returns a copy of list without s, and whether s was in it.
+func removeString(list []string, s string) ([]string, bool) {
+	res := make([]string, 0, len(list))
+	for _, v := range list {
+		if v != s {
+			res = append(res, v)
+		}
+	}
+
+	return res, len(res) != len(list)
+}
+
+// resetCycles forgets the cycles found so far, as they may have been
+// broken by removing dependencies.
+func (d *Dependency) resetCycles() {
+	for _, m := range []*sync.Map{&d.knownCyclers, &d.cyclicDeps} {
+		m.Range(func(key, _ interface{}) bool {
+			m.Delete(key)
+			return true
+		})
+	}
+}
+
 func trimToCycle(cycle, offender string) (string, error) {
 	if cycle == "" || offender == "" {
 		return "", fmt.Errorf("cycle or offender is empty")
@@ -1,6 +1,7 @@
 package dependency
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -96,6 +97,182 @@ func TestDependency_Add(t *testing.T) {
 	}
 }
 
+func TestDependency_Remove(t *testing.T) {
+	tests := []struct {
+		name      string
+		depender  string
+		dependent string
+		wantErr   bool
+	}{
+		{"Existing", "a", "b", false},
+
+		{"Missing dependency", "b", "a", true},
+		{"Missing dependent", "", "b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewWithCycles(true)
+			d.Add("a", "b")
+			d.Add("a", "b")
+			d.Add("a", "c")
+			d.Suppress("a", "b", "legacy")
+
+			if err := d.Remove(tt.depender, tt.dependent); (err != nil) != tt.wantErr {
+				t.Fatalf("Dependency.Remove() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := d.Dependencies("a"); !reflect.DeepEqual(got, []string{"c"}) {
+				t.Errorf("dependencies after Remove() = %v, want [c]", got)
+			}
+
+			if _, ok := d.visibilities["b"]; ok {
+				t.Errorf("visibility of b kept after Remove()")
+			}
+
+			if n := d.ImportSites("a", "b"); n != 0 {
+				t.Errorf("ImportSites() after Remove() = %d, want 0", n)
+			}
+
+			if _, ok := d.Suppressed("a", "b"); ok {
+				t.Errorf("suppression kept after Remove()")
+			}
+		})
+	}
+}
+
+func TestDependency_Remove_cycle(t *testing.T) {
+	d := New()
+	d.Add("a", "b")
+	d.Add("b", "c")
+
+	if err := d.Add("c", "a"); err == nil {
+		t.Fatalf("Dependency.Add() expected a cycle error")
+	}
+
+	if err := d.Remove("c", "a"); err != nil {
+		t.Fatalf("Dependency.Remove() error = %v", err)
+	}
+
+	if cycles, ok := d.CheckCyclicDependencies(); !ok {
+		t.Errorf("CheckCyclicDependencies() after Remove() = %v, want none", cycles)
+	}
+
+	// The classes of the broken cycle must be checked again.
+	if err := d.Add("c", "a"); err == nil {
+		t.Errorf("Dependency.Add() expected a cycle error after Remove()")
+	}
+}
+
+func TestDependency_RemoveNode(t *testing.T) {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "b", File: "B.java"})
+	d.Add("a", "b")
+	d.Add("b", "c")
+	d.Add("b", "a")
+	d.Add("c", "d")
+
+	if err := d.RemoveNode("b"); err != nil {
+		t.Fatalf("Dependency.RemoveNode() error = %v", err)
+	}
+
+	if got, want := d.Classes(), []string{"c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("classes after RemoveNode() = %v, want %v", got, want)
+	}
+
+	if _, ok := d.Node("b"); ok {
+		t.Errorf("node kept after RemoveNode()")
+	}
+
+	if len(d.visibilities) != 1 || len(d.sites) != 1 {
+		t.Errorf("RemoveNode() left visibilities %v and sites %v, want only c -> d", d.visibilities, d.sites)
+	}
+
+	if len(d.StronglyConnectedComponents()) != 0 {
+		t.Errorf("cycle through b kept after RemoveNode()")
+	}
+
+	if err := d.RemoveNode("b"); err == nil {
+		t.Errorf("Dependency.RemoveNode() of a removed class expected error")
+	}
+}
+
+func TestDependency_SetDependencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		dependents []string
+		want       []string
+		wantSites  int
+		wantErr    bool
+	}{
+		{"Replace", []string{"c", "d", "d"}, []string{"c", "d"}, 2, false},
+		{"Clear", nil, nil, 0, false},
+
+		{"Empty dependent", []string{""}, nil, 0, true},
+		{"Dependency cycle", []string{"a"}, nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New()
+			d.Add("a", "b")
+			d.Add("b", "c")
+			d.Suppress("b", "c", "legacy")
+
+			if err := d.SetDependencies("b", tt.dependents); (err != nil) != tt.wantErr {
+				t.Fatalf("Dependency.SetDependencies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := d.Dependencies("b"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dependencies after SetDependencies() = %v, want %v", got, tt.want)
+			}
+
+			if n := d.ImportSites("b", "d"); n != tt.wantSites {
+				t.Errorf("ImportSites(b, d) = %d, want %d", n, tt.wantSites)
+			}
+
+			if _, ok := d.Suppressed("b", "c"); ok {
+				t.Errorf("suppression kept after SetDependencies()")
+			}
+
+			for _, dependent := range []string{"c", "d"} {
+				isDependency := false
+				for _, w := range tt.want {
+					isDependency = isDependency || w == dependent
+				}
+
+				if got := d.Dependents(dependent); (len(got) == 1) != isDependency {
+					t.Errorf("dependents of %s = %v, want them to follow the dependencies", dependent, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDependency_Clone(t *testing.T) {
+	d := testGraph()
+	c := d.Clone()
+
+	assertSameGraph(t, c, d)
+
+	c.Add("new", "dependency")
+	c.RemoveNode("com.a.A")
+
+	if _, ok := d.Node("com.a.A"); !ok {
+		t.Errorf("changing the clone changed the original")
+	}
+
+	if len(d.Dependencies("new")) != 0 {
+		t.Errorf("dependency added to the clone shows up in the original")
+	}
+}
+
 func TestDependency_mustAddVisibility(t *testing.T) {
 	type args struct {
 		stalked string
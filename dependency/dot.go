@@ -0,0 +1,180 @@
+package dependency
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Ways to group the nodes of an exported graph.
+const (
+	ClusterNone    = ""
+	ClusterPackage = "package"
+	ClusterModule  = "module"
+)
+
+// DOTOptions controls what WriteDOT writes.
+type DOTOptions struct {
+	// Cluster groups the nodes by package or module. See the Cluster
+	// constants.
+	Cluster string
+
+	// Highlight colors the classes that are part of a strongly connected
+	// component, as well as the dependencies that close cycles. Suppressed
+	// dependencies are drawn dashed.
+	Highlight bool
+
+	// Focus restricts the graph to the classes that are at most Hops
+	// dependencies or dependents away from it.
+	Focus string
+	Hops  int
+}
+
+// WriteDOT writes the dependency graph in Graphviz DOT format.
+func (d *Dependency) WriteDOT(w io.Writer, opts DOTOptions) error {
+	if opts.Cluster != ClusterNone && opts.Cluster != ClusterPackage && opts.Cluster != ClusterModule {
+		return fmt.Errorf("unknown cluster mode: %s", opts.Cluster)
+	}
+
+	classes := d.Classes()
+	if opts.Focus != "" {
+		var err error
+
+		classes, err = d.neighbourhood(opts.Focus, opts.Hops)
+		if err != nil {
+			return err
+		}
+	}
+
+	included := make(map[string]struct{}, len(classes))
+	for _, class := range classes {
+		included[class] = struct{}{}
+	}
+
+	sccOf := make(map[string]int)
+	if opts.Highlight {
+		for i, scc := range d.StronglyConnectedComponents() {
+			for _, class := range scc {
+				sccOf[class] = i
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph dependencies {")
+	fmt.Fprintln(bw, "  node [shape=box];")
+
+	clusters := make(map[string][]string)
+	for _, class := range classes {
+		name := d.clusterOf(class, opts.Cluster)
+		clusters[name] = append(clusters[name], class)
+	}
+
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for i, name := range names {
+		indent := "  "
+		if name != "" {
+			fmt.Fprintf(bw, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(bw, "    label=%q;\n", name)
+			indent = "    "
+		}
+
+		for _, class := range clusters[name] {
+			if _, ok := sccOf[class]; ok {
+				fmt.Fprintf(bw, "%s%q [style=filled, fillcolor=lightpink];\n", indent, class)
+				continue
+			}
+
+			fmt.Fprintf(bw, "%s%q;\n", indent, class)
+		}
+
+		if name != "" {
+			fmt.Fprintln(bw, "  }")
+		}
+	}
+
+	for _, from := range classes {
+		for _, to := range d.Dependencies(from) {
+			if _, ok := included[to]; !ok {
+				continue
+			}
+
+			fromSCC, fromOK := sccOf[from]
+			toSCC, toOK := sccOf[to]
+
+			if _, suppressed := d.Suppressed(from, to); suppressed && opts.Highlight {
+				fmt.Fprintf(bw, "  %q -> %q [style=dashed, color=gray];\n", from, to)
+				continue
+			}
+
+			if fromOK && toOK && fromSCC == toSCC {
+				fmt.Fprintf(bw, "  %q -> %q [color=red];\n", from, to)
+				continue
+			}
+
+			fmt.Fprintf(bw, "  %q -> %q;\n", from, to)
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+// clusterOf returns the name of the cluster the class belongs to, or an
+// empty string if it doesn't belong to any.
+func (d *Dependency) clusterOf(class, mode string) string {
+	switch mode {
+	case ClusterPackage:
+		return PackageOf(class)
+	case ClusterModule:
+		n, _ := d.Node(class)
+		return n.Module
+	}
+
+	return ""
+}
+
+// neighbourhood returns the classes that can be reached from class in at
+// most hops steps, following both dependencies and dependents.
+func (d *Dependency) neighbourhood(class string, hops int) ([]string, error) {
+	if _, ok := d.Node(class); !ok && len(d.Dependencies(class)) == 0 && len(d.Dependents(class)) == 0 {
+		return nil, fmt.Errorf("class not found: %s", class)
+	}
+
+	seen := map[string]struct{}{class: {}}
+	frontier := []string{class}
+
+	for i := 0; i < hops && len(frontier) != 0; i++ {
+		var next []string
+		for _, c := range frontier {
+			for _, n := range append(d.Dependencies(c), d.Dependents(c)...) {
+				if _, ok := seen[n]; ok {
+					continue
+				}
+
+				seen[n] = struct{}{}
+				next = append(next, n)
+			}
+		}
+
+		frontier = next
+	}
+
+	classes := make([]string, 0, len(seen))
+	for c := range seen {
+		classes = append(classes, c)
+	}
+
+	sort.Strings(classes)
+
+	return classes, nil
+}
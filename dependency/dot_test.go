@@ -0,0 +1,64 @@
+package dependency
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDependency_WriteDOT(t *testing.T) {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "com.a.A", Module: "a"})
+	d.SetNode(Node{Name: "com.b.B", Module: "b"})
+	d.Add("com.a.A", "com.b.B")
+	d.Add("com.b.B", "com.a.A")
+	d.Add("com.b.B", "com.c.C")
+	d.Add("com.c.C", "com.d.D")
+
+	tests := []struct {
+		name    string
+		opts    DOTOptions
+		want    []string
+		notWant []string
+		wantErr bool
+	}{
+		{"plain", DOTOptions{},
+			[]string{`"com.a.A" -> "com.b.B";`, `"com.c.C" -> "com.d.D";`},
+			[]string{"subgraph", "color=red"}, false},
+		{"packages", DOTOptions{Cluster: ClusterPackage},
+			[]string{`label="com.a";`, `label="com.d";`},
+			nil, false},
+		{"modules", DOTOptions{Cluster: ClusterModule},
+			[]string{`label="a";`, `label="b";`},
+			[]string{`label="";`}, false},
+		{"highlight", DOTOptions{Highlight: true},
+			[]string{`"com.a.A" [style=filled, fillcolor=lightpink];`, `"com.a.A" -> "com.b.B" [color=red];`, `"com.b.B" -> "com.c.C";`},
+			nil, false},
+		{"focus", DOTOptions{Focus: "com.a.A", Hops: 1},
+			[]string{`"com.a.A" -> "com.b.B";`},
+			[]string{"com.c.C"}, false},
+
+		{"unknown cluster", DOTOptions{Cluster: "whatever"}, nil, nil, true},
+		{"unknown focus", DOTOptions{Focus: "com.x.X"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := d.WriteDOT(&buf, tt.opts); (err != nil) != tt.wantErr {
+				t.Fatalf("Dependency.WriteDOT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Dependency.WriteDOT() output is missing %q:\n%s", want, buf.String())
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("Dependency.WriteDOT() output contains %q:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package dependency
+
+import "sort"
+
+// cycleSearchBudget limits how many steps the cycle enumeration may take
+// in each component in a single round of the feedback arc set heuristic, as
+// the number of simple cycles can grow exponentially with its size.
+const cycleSearchBudget = 200000
+
+// CycleBreak is a dependency that is suggested to be removed in order to
+// break dependency cycles.
+//
+// Cycles is the number of cycles the dependency took part in when it was
+// picked, and Sites is the number of import statements backing it. If
+// Estimated is set, there were too many cycles to count them all, and
+// Cycles is only a lower bound.
+type CycleBreak struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Cycles    int    `json:"cycles"`
+	Sites     int    `json:"sites"`
+	Estimated bool   `json:"estimated,omitempty"`
+}
+
+// FeedbackArcSet holds the dependencies that need to be removed from a
+// strongly connected component to make it acyclic.
+type FeedbackArcSet struct {
+	Component []string     `json:"component"`
+	Breaks    []CycleBreak `json:"breaks"`
+}
+
+// Imports returns the number of import statements that need to be removed
+// to apply all the suggested breaks.
+func (f FeedbackArcSet) Imports() int {
+	var imports int
+	for _, b := range f.Breaks {
+		imports += b.Sites
+	}
+
+	return imports
+}
+
+// FeedbackArcSets suggests which dependencies to remove to get rid of all
+// dependency cycles, separately for each strongly connected component.
+//
+// Finding the minimum feedback arc set is NP-hard, so a greedy heuristic
+// is used: the dependency that takes part in the most cycles relative to
+// the number of imports backing it is removed, until no cycles are left.
+func (d *Dependency) FeedbackArcSets() []FeedbackArcSet {
+	g := d.cycleSnapshot()
+
+	var sets []FeedbackArcSet
+	for _, comp := range g.sccs() {
+		sub := g.induced(comp)
+
+		set := FeedbackArcSet{Component: append([]string(nil), sub.names...)}
+		sort.Strings(set.Component)
+
+		set.Breaks = sub.feedbackArcSet(d.ImportSites)
+
+		sets = append(sets, set)
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Component[0] < sets[j].Component[0]
+	})
+
+	return sets
+}
+
+// induced returns the subgraph made up of the given nodes and the
+// dependencies between them.
+func (g *graph) induced(nodes []int) *graph {
+	sub := graph{
+		names: make([]string, len(nodes)),
+		index: make(map[string]int, len(nodes)),
+		out:   make([][]int, len(nodes)),
+		in:    make([][]int, len(nodes)),
+	}
+
+	local := make(map[int]int, len(nodes))
+	for i, v := range nodes {
+		local[v] = i
+		sub.names[i] = g.names[v]
+		sub.index[g.names[v]] = i
+	}
+
+	for i, v := range nodes {
+		for _, w := range g.out[v] {
+			j, ok := local[w]
+			if !ok {
+				continue
+			}
+
+			sub.out[i] = append(sub.out[i], j)
+			sub.in[j] = append(sub.in[j], i)
+		}
+	}
+
+	return &sub
+}
+
+func (g *graph) removeEdge(from, to int) {
+	g.out[from] = without(g.out[from], to)
+	g.in[to] = without(g.in[to], from)
+}
+
+func without(list []int, v int) []int {
+	res := list[:0]
+	for _, w := range list {
+		if w != v {
+			res = append(res, w)
+		}
+	}
+
+	return res
+}
+
+// feedbackArcSet removes dependencies from the graph until it's acyclic,
+// and returns the removed ones in the order they were picked.
+func (g *graph) feedbackArcSet(sites func(from, to string) int) []CycleBreak {
+	var breaks []CycleBreak
+
+	for {
+		comps := g.sccs()
+		if len(comps) == 0 {
+			return breaks
+		}
+
+		counts, exhausted := g.countCycles(comps)
+
+		var (
+			best       [2]int
+			bestScore  = -1.0
+			bestSites  int
+			bestCycles int
+			bestComp   int
+		)
+
+		for ci, comp := range comps {
+			members := make(map[int]struct{}, len(comp))
+			for _, v := range comp {
+				members[v] = struct{}{}
+			}
+
+			for _, v := range comp {
+				for _, w := range g.out[v] {
+					if _, ok := members[w]; !ok {
+						continue
+					}
+
+					e := [2]int{v, w}
+
+					n := sites(g.names[v], g.names[w])
+					if n < 1 {
+						n = 1
+					}
+
+					// Every edge within a component is part of at least one
+					// cycle, even if the search ran out of budget before
+					// finding it.
+					c := counts[e]
+					if c < 1 {
+						c = 1
+					}
+
+					score := float64(c) / float64(n)
+					if score > bestScore || (score == bestScore && g.lessEdge(e, best)) {
+						best, bestScore, bestSites, bestCycles, bestComp = e, score, n, c, ci
+					}
+				}
+			}
+		}
+
+		breaks = append(breaks, CycleBreak{
+			From:      g.names[best[0]],
+			To:        g.names[best[1]],
+			Cycles:    bestCycles,
+			Sites:     bestSites,
+			Estimated: exhausted[bestComp],
+		})
+
+		g.removeEdge(best[0], best[1])
+	}
+}
+
+func (g *graph) lessEdge(a, b [2]int) bool {
+	if g.names[a[0]] != g.names[b[0]] {
+		return g.names[a[0]] < g.names[b[0]]
+	}
+
+	return g.names[a[1]] < g.names[b[1]]
+}
+
+// countCycles enumerates the simple cycles within the given components and
+// counts how many of them each dependency takes part in. Each cycle is only
+// found once, from its lowest indexed node. Each component has a budget of
+// its own; exhausted tells which ones ran out of it before all of their
+// cycles were found.
+func (g *graph) countCycles(comps [][]int) (counts map[[2]int]int, exhausted []bool) {
+	compOf := make([]int, len(g.names))
+	for i := range compOf {
+		compOf[i] = -1
+	}
+
+	for c, comp := range comps {
+		for _, v := range comp {
+			compOf[v] = c
+		}
+	}
+
+	counts = make(map[[2]int]int)
+	exhausted = make([]bool, len(comps))
+
+	budgets := make([]int, len(comps))
+	for c := range budgets {
+		budgets[c] = cycleSearchBudget
+	}
+
+	onPath := make([]bool, len(g.names))
+
+	var (
+		path  []int
+		visit func(s, v int)
+	)
+
+	visit = func(s, v int) {
+		c := compOf[s]
+
+		for _, w := range g.out[v] {
+			if budgets[c] <= 0 {
+				exhausted[c] = true
+				return
+			}
+			budgets[c]--
+
+			if w == s {
+				for i := 0; i < len(path)-1; i++ {
+					counts[[2]int{path[i], path[i+1]}]++
+				}
+				counts[[2]int{v, s}]++
+
+				continue
+			}
+
+			if w < s || onPath[w] || compOf[w] != compOf[s] {
+				continue
+			}
+
+			onPath[w] = true
+			path = append(path, w)
+
+			visit(s, w)
+
+			path = path[:len(path)-1]
+			onPath[w] = false
+		}
+	}
+
+	for s := range g.names {
+		if compOf[s] == -1 {
+			continue
+		}
+
+		onPath[s] = true
+		path = append(path[:0], s)
+
+		visit(s, s)
+
+		onPath[s] = false
+	}
+
+	return counts, exhausted
+}
@@ -0,0 +1,100 @@
+package dependency
+
+import (
+	"testing"
+)
+
+func TestDependency_FeedbackArcSets(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges [][2]string
+		want  []CycleBreak
+	}{
+		{"acyclic", [][2]string{{"a", "b"}, {"b", "c"}}, nil},
+		{"direct cycle", [][2]string{{"a", "b"}, {"b", "a"}},
+			[]CycleBreak{{From: "a", To: "b", Cycles: 1, Sites: 1}}},
+		{"shared edge", [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"b", "d"}, {"d", "a"}},
+			[]CycleBreak{{From: "a", To: "b", Cycles: 2, Sites: 1}}},
+		{"cheaper edge", [][2]string{{"a", "b"}, {"a", "b"}, {"a", "b"}, {"b", "a"}},
+			[]CycleBreak{{From: "b", To: "a", Cycles: 1, Sites: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewWithCycles(true)
+			for _, e := range tt.edges {
+				d.Add(e[0], e[1])
+			}
+
+			var got []CycleBreak
+			for _, set := range d.FeedbackArcSets() {
+				got = append(got, set.Breaks...)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Dependency.FeedbackArcSets() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Dependency.FeedbackArcSets()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+
+			for _, b := range got {
+				d.deps[b.From] = withoutClass(d.deps[b.From], b.To)
+			}
+
+			if sccs := d.StronglyConnectedComponents(); len(sccs) != 0 {
+				t.Errorf("cycles left after applying breaks: %v", sccs)
+			}
+		})
+	}
+}
+
+func withoutClass(list []string, s string) []string {
+	var res []string
+	for _, v := range list {
+		if v != s {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func TestGraph_countCycles_budget(t *testing.T) {
+	d := NewWithCycles(true)
+
+	// A complete graph has far too many cycles to count them all.
+	var clique []string
+	for c := 'A'; c <= 'J'; c++ {
+		clique = append(clique, "a."+string(c))
+	}
+
+	for _, from := range clique {
+		for _, to := range clique {
+			if from != to {
+				d.Add(from, to)
+			}
+		}
+	}
+
+	d.Add("z.A", "z.B")
+	d.Add("z.B", "z.A")
+
+	g := d.cycleSnapshot()
+	comps := g.sccs()
+
+	counts, exhausted := g.countCycles(comps)
+
+	for c, comp := range comps {
+		want := g.names[comp[0]] < "z"
+		if exhausted[c] != want {
+			t.Errorf("exhausted of the component of %s = %v, want %v", g.names[comp[0]], exhausted[c], want)
+		}
+	}
+
+	if got := counts[[2]int{g.index["z.A"], g.index["z.B"]}]; got != 1 {
+		t.Errorf("cycles of z.A -> z.B = %d, want 1", got)
+	}
+}
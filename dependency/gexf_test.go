@@ -0,0 +1,40 @@
+package dependency
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestDependency_WriteGEXF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testGraph().WriteGEXF(&buf); err != nil {
+		t.Fatalf("Dependency.WriteGEXF() error = %v", err)
+	}
+
+	var doc gexf
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("failed parsing output: %v", err)
+	}
+
+	if len(doc.Graph.Attributes) != 2 || len(doc.Graph.Attributes[0].Attributes) != len(nodeAttributes) {
+		t.Errorf("unexpected attribute declarations: %+v", doc.Graph.Attributes)
+	}
+
+	if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 3 {
+		t.Fatalf("got %d nodes and %d edges, want 4 and 3", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	values := make(map[string]string)
+	for _, v := range doc.Graph.Nodes[1].Values {
+		values[v.For] = v.Value
+	}
+
+	if doc.Graph.Nodes[1].Label != "com.b.B" || values["abstract"] != "true" || values["sourceSet"] != "test" {
+		t.Errorf("unexpected second node: %+v", doc.Graph.Nodes[1])
+	}
+
+	if e := doc.Graph.Edges[0]; e.Source != "com.a.A" || e.Target != "com.b.B" || e.Weight != 2 {
+		t.Errorf("unexpected first edge: %+v", e)
+	}
+}
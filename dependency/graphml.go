@@ -0,0 +1,224 @@
+package dependency
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// exportNode is a class with all the attributes the graph exporters
+// attach to it. SCC is -1 for classes that are not part of any cycle.
+type exportNode struct {
+	Node
+	Package  string
+	Declared bool
+	SCC      int
+	Metrics  NodeMetrics
+}
+
+// exportEdge is a dependency with all the attributes the graph exporters
+// attach to it. Cycle is true if both ends are in the same SCC, and the
+// dependency is not suppressed.
+type exportEdge struct {
+	From, To string
+	Kind     string
+	Sites    int
+	Cycle    bool
+}
+
+// exportGraph collects the nodes and edges along with their attributes,
+// both in alphabetical order.
+func (d *Dependency) exportGraph() ([]exportNode, []exportEdge) {
+	sccOf := make(map[string]int)
+	for i, scc := range d.StronglyConnectedComponents() {
+		for _, class := range scc {
+			sccOf[class] = i
+		}
+	}
+
+	metrics := d.NodeMetrics()
+
+	nodes := make([]exportNode, len(metrics))
+	for i, m := range metrics {
+		n, ok := d.Node(m.Class)
+		n.Name = m.Class
+
+		scc, inSCC := sccOf[m.Class]
+		if !inSCC {
+			scc = -1
+		}
+
+		nodes[i] = exportNode{Node: n, Package: PackageOf(m.Class), Declared: ok, SCC: scc, Metrics: m}
+	}
+
+	var edges []exportEdge
+	for _, n := range nodes {
+		for _, to := range d.Dependencies(n.Name) {
+			toSCC, ok := sccOf[to]
+			_, suppressed := d.Suppressed(n.Name, to)
+
+			edges = append(edges, exportEdge{
+				From:  n.Name,
+				To:    to,
+				Kind:  EdgeKindImport,
+				Sites: d.ImportSites(n.Name, to),
+				Cycle: ok && toSCC == n.SCC && !suppressed,
+			})
+		}
+	}
+
+	return nodes, edges
+}
+
+// attribute describes a node or edge attribute of the exported graphs.
+type attribute struct {
+	name, kind string
+}
+
+var (
+	nodeAttributes = []attribute{
+		{"package", "string"},
+		{"module", "string"},
+		{"sourceSet", "string"},
+		{"file", "string"},
+		{"declared", "boolean"},
+		{"abstract", "boolean"},
+		{"scc", "int"},
+		{"inDegree", "int"},
+		{"outDegree", "int"},
+		{"reach", "int"},
+		{"betweenness", "double"},
+		{"pageRank", "double"},
+	}
+
+	edgeAttributes = []attribute{
+		{"kind", "string"},
+		{"count", "int"},
+		{"cycle", "boolean"},
+	}
+)
+
+// values returns the node attribute values in the order of nodeAttributes.
+func (n exportNode) values() []string {
+	return []string{
+		n.Package,
+		n.Module,
+		n.SourceSet,
+		n.File,
+		strconv.FormatBool(n.Declared),
+		strconv.FormatBool(n.Abstract),
+		strconv.Itoa(n.SCC),
+		strconv.Itoa(n.Metrics.InDegree),
+		strconv.Itoa(n.Metrics.OutDegree),
+		strconv.Itoa(n.Metrics.Reach),
+		strconv.FormatFloat(n.Metrics.Betweenness, 'g', -1, 64),
+		strconv.FormatFloat(n.Metrics.PageRank, 'g', -1, 64),
+	}
+}
+
+// values returns the edge attribute values in the order of edgeAttributes.
+func (e exportEdge) values() []string {
+	return []string{
+		e.Kind,
+		strconv.Itoa(e.Sites),
+		strconv.FormatBool(e.Cycle),
+	}
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the dependency graph in GraphML format, as read by
+// yEd and Gephi among others. Nodes are identified by their class names,
+// and carry their package, module, SCC and metrics as attributes. Edges
+// carry their kind, the number of imports backing them, and whether
+// they're part of a cycle.
+func (d *Dependency) WriteGraphML(w io.Writer) error {
+	nodes, edges := d.exportGraph()
+
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  []graphMLKey{{ID: "label", For: "node", Name: "label", Type: "string"}},
+		Graph: graphMLGraph{ID: "dependencies", EdgeDefault: "directed"},
+	}
+
+	for _, a := range nodeAttributes {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: a.name, For: "node", Name: a.name, Type: a.kind})
+	}
+
+	for _, a := range edgeAttributes {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: a.name, For: "edge", Name: a.name, Type: a.kind})
+	}
+
+	for _, n := range nodes {
+		node := graphMLNode{ID: n.Name, Data: []graphMLData{{Key: "label", Value: n.Name}}}
+		for i, v := range n.values() {
+			node.Data = append(node.Data, graphMLData{Key: nodeAttributes[i].name, Value: v})
+		}
+
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for i, e := range edges {
+		edge := graphMLEdge{ID: "e" + strconv.Itoa(i), Source: e.From, Target: e.To}
+		for j, v := range e.values() {
+			edge.Data = append(edge.Data, graphMLData{Key: edgeAttributes[j].name, Value: v})
+		}
+
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,47 @@
+package dependency
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestDependency_WriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testGraph().WriteGraphML(&buf); err != nil {
+		t.Fatalf("Dependency.WriteGraphML() error = %v", err)
+	}
+
+	var doc graphML
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("failed parsing output: %v", err)
+	}
+
+	if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 3 {
+		t.Fatalf("got %d nodes and %d edges, want 4 and 3", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	node := dataOf(doc.Graph.Nodes[0].Data)
+	if doc.Graph.Nodes[0].ID != "com.a.A" || node["module"] != "a" || node["scc"] != "0" || node["package"] != "com.a" {
+		t.Errorf("unexpected first node: %+v", doc.Graph.Nodes[0])
+	}
+
+	edge := dataOf(doc.Graph.Edges[0].Data)
+	if doc.Graph.Edges[0].Source != "com.a.A" || edge["count"] != "2" || edge["cycle"] != "true" || edge["kind"] != "import" {
+		t.Errorf("unexpected first edge: %+v", doc.Graph.Edges[0])
+	}
+
+	edge = dataOf(doc.Graph.Edges[2].Data)
+	if doc.Graph.Edges[2].Target != "java.util.List" || edge["cycle"] != "false" {
+		t.Errorf("unexpected last edge: %+v", doc.Graph.Edges[2])
+	}
+}
+
+func dataOf(data []graphMLData) map[string]string {
+	m := make(map[string]string)
+	for _, d := range data {
+		m[d.Key] = d.Value
+	}
+
+	return m
+}
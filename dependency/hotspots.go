@@ -0,0 +1,328 @@
+package dependency
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+)
+
+const (
+	pageRankDamping    = 0.85
+	pageRankIterations = 100
+	pageRankTolerance  = 1e-9
+)
+
+// NodeMetrics holds the class level metrics used to find hotspots.
+//
+// Reach is the size of the transitive closure, i.e. the number of classes
+// the class depends on directly or indirectly. Betweenness is the number of
+// shortest dependency paths between other classes that pass through the
+// class, and PageRank tells how much of the graph ultimately leans on it.
+type NodeMetrics struct {
+	Class       string  `json:"class"`
+	InDegree    int     `json:"inDegree"`
+	OutDegree   int     `json:"outDegree"`
+	Reach       int     `json:"reach"`
+	Betweenness float64 `json:"betweenness"`
+	PageRank    float64 `json:"pageRank"`
+}
+
+// graph is an index based snapshot of the dependencies that the
+// graph algorithms can work on without locking.
+type graph struct {
+	names []string
+	index map[string]int
+	out   [][]int
+	in    [][]int
+}
+
+func (d *Dependency) snapshot() *graph {
+	return d.snapshotOf(d.Dependencies)
+}
+
+// cycleSnapshot is a snapshot without the suppressed dependencies, to be
+// used when looking for cycles.
+func (d *Dependency) cycleSnapshot() *graph {
+	return d.snapshotOf(d.activeDependencies)
+}
+
+func (d *Dependency) snapshotOf(dependencies func(depender string) []string) *graph {
+	names := d.Classes()
+
+	g := graph{
+		names: names,
+		index: make(map[string]int, len(names)),
+		out:   make([][]int, len(names)),
+		in:    make([][]int, len(names)),
+	}
+
+	for i, name := range names {
+		g.index[name] = i
+	}
+
+	for i, name := range names {
+		for _, dep := range dependencies(name) {
+			j := g.index[dep]
+
+			g.out[i] = append(g.out[i], j)
+			g.in[j] = append(g.in[j], i)
+		}
+	}
+
+	return &g
+}
+
+// NodeMetrics calculates the metrics of every class in the graph, sorted
+// by class name.
+func (d *Dependency) NodeMetrics() []NodeMetrics {
+	g := d.snapshot()
+
+	reach, betweenness := g.reachAndBetweenness()
+	pageRank := g.pageRank()
+
+	metrics := make([]NodeMetrics, len(g.names))
+	for i, name := range g.names {
+		metrics[i] = NodeMetrics{
+			Class:       name,
+			InDegree:    len(g.in[i]),
+			OutDegree:   len(g.out[i]),
+			Reach:       reach[i],
+			Betweenness: betweenness[i],
+			PageRank:    pageRank[i],
+		}
+	}
+
+	return metrics
+}
+
+// TopNodeMetrics sorts the metrics in descending order by the given key
+// and returns the first n of them. If n is not positive, all of them are
+// returned. Valid keys are in, out, reach, betweenness and pagerank.
+func TopNodeMetrics(metrics []NodeMetrics, by string, n int) ([]NodeMetrics, error) {
+	var key func(m NodeMetrics) float64
+
+	switch by {
+	case "in":
+		key = func(m NodeMetrics) float64 { return float64(m.InDegree) }
+	case "out":
+		key = func(m NodeMetrics) float64 { return float64(m.OutDegree) }
+	case "reach":
+		key = func(m NodeMetrics) float64 { return float64(m.Reach) }
+	case "betweenness":
+		key = func(m NodeMetrics) float64 { return m.Betweenness }
+	case "pagerank":
+		key = func(m NodeMetrics) float64 { return m.PageRank }
+	default:
+		return nil, fmt.Errorf("unknown sort key: %s", by)
+	}
+
+	sorted := append([]NodeMetrics(nil), metrics...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return key(sorted[i]) > key(sorted[j])
+	})
+
+	if n > 0 && n < len(sorted) {
+		sorted = sorted[:n]
+	}
+
+	return sorted, nil
+}
+
+// reachAndBetweenness runs a breadth first search from every class, which
+// is enough to get both the transitive closure sizes and, using Brandes'
+// algorithm, the betweenness centrality. Sources are spread across as many
+// workers as there are CPUs.
+func (g *graph) reachAndBetweenness() ([]int, []float64) {
+	n := len(g.names)
+
+	reach := make([]int, n)
+	betweenness := make([]float64, n)
+
+	sources := make(chan int)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	workers := runtime.NumCPU()
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			local := make([]float64, n)
+			for s := range sources {
+				reach[s] = g.brandes(s, local)
+			}
+
+			mu.Lock()
+			for i, b := range local {
+				betweenness[i] += b
+			}
+			mu.Unlock()
+		}()
+	}
+
+	for s := 0; s < n; s++ {
+		sources <- s
+	}
+	close(sources)
+
+	wg.Wait()
+
+	return reach, betweenness
+}
+
+// brandes accumulates the dependencies of source s into betweenness and
+// returns the number of classes reachable from s.
+func (g *graph) brandes(s int, betweenness []float64) int {
+	n := len(g.names)
+
+	sigma := make([]float64, n)
+	dist := make([]int, n)
+	delta := make([]float64, n)
+	preds := make([][]int, n)
+
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	sigma[s] = 1
+	dist[s] = 0
+
+	order := make([]int, 0)
+	queue := []int{s}
+	for len(queue) != 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		order = append(order, v)
+
+		for _, w := range g.out[v] {
+			if dist[w] < 0 {
+				dist[w] = dist[v] + 1
+				queue = append(queue, w)
+			}
+
+			if dist[w] == dist[v]+1 {
+				sigma[w] += sigma[v]
+				preds[w] = append(preds[w], v)
+			}
+		}
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		w := order[i]
+		for _, v := range preds[w] {
+			delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+		}
+
+		if w != s {
+			betweenness[w] += delta[w]
+		}
+	}
+
+	return len(order) - 1
+}
+
+// pageRank calculates the PageRank of every class, where depending on a
+// class is a vote for its importance. Classes without dependencies spread
+// their rank evenly across the graph.
+func (g *graph) pageRank() []float64 {
+	n := len(g.names)
+	if n == 0 {
+		return nil
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	for iter := 0; iter < pageRankIterations; iter++ {
+		var dangling float64
+		for i := range rank {
+			if len(g.out[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-pageRankDamping)/float64(n) + pageRankDamping*dangling/float64(n)
+
+		next := make([]float64, n)
+		for i := range next {
+			next[i] = base
+		}
+
+		for i, deps := range g.out {
+			share := pageRankDamping * rank[i] / float64(len(deps))
+			for _, j := range deps {
+				next[j] += share
+			}
+		}
+
+		var diff float64
+		for i := range rank {
+			diff += math.Abs(next[i] - rank[i])
+		}
+
+		rank = next
+
+		if diff < pageRankTolerance {
+			break
+		}
+	}
+
+	return rank
+}
+
+// WriteNodeMetricsTable writes the metrics as an aligned, human
+// readable table.
+func WriteNodeMetricsTable(w io.Writer, metrics []NodeMetrics) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "CLASS\tIN\tOUT\tREACH\tBETWEENNESS\tPAGERANK")
+	for _, m := range metrics {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%.6f\n",
+			m.Class, m.InDegree, m.OutDegree, m.Reach, m.Betweenness, m.PageRank)
+	}
+
+	return tw.Flush()
+}
+
+// WriteNodeMetricsCSV writes the metrics as CSV with a header row.
+func WriteNodeMetricsCSV(w io.Writer, metrics []NodeMetrics) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"class", "inDegree", "outDegree", "reach", "betweenness", "pageRank"})
+	for _, m := range metrics {
+		cw.Write([]string{
+			m.Class,
+			strconv.Itoa(m.InDegree),
+			strconv.Itoa(m.OutDegree),
+			strconv.Itoa(m.Reach),
+			formatFloat(m.Betweenness),
+			strconv.FormatFloat(m.PageRank, 'f', 8, 64),
+		})
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// WriteNodeMetricsJSON writes the metrics as an indented JSON array.
+func WriteNodeMetricsJSON(w io.Writer, metrics []NodeMetrics) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(metrics)
+}
@@ -0,0 +1,82 @@
+package dependency
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDependency_NodeMetrics(t *testing.T) {
+	d := NewWithCycles(true)
+
+	// a and b both reach d and e through the c hub
+	for _, edge := range [][2]string{
+		{"a", "c"},
+		{"b", "c"},
+		{"c", "d"},
+		{"c", "e"},
+	} {
+		d.Add(edge[0], edge[1])
+	}
+
+	want := map[string]NodeMetrics{
+		"a": {Class: "a", InDegree: 0, OutDegree: 1, Reach: 3, Betweenness: 0},
+		"b": {Class: "b", InDegree: 0, OutDegree: 1, Reach: 3, Betweenness: 0},
+		"c": {Class: "c", InDegree: 2, OutDegree: 2, Reach: 2, Betweenness: 4},
+		"d": {Class: "d", InDegree: 1, OutDegree: 0, Reach: 0, Betweenness: 0},
+		"e": {Class: "e", InDegree: 1, OutDegree: 0, Reach: 0, Betweenness: 0},
+	}
+
+	var total float64
+	for _, m := range d.NodeMetrics() {
+		total += m.PageRank
+
+		w := want[m.Class]
+		w.PageRank = m.PageRank
+		if m != w {
+			t.Errorf("Dependency.NodeMetrics() = %+v, want %+v", m, w)
+		}
+	}
+
+	if math.Abs(total-1) > 1e-6 {
+		t.Errorf("sum of PageRank = %v, want 1", total)
+	}
+}
+
+func TestTopNodeMetrics(t *testing.T) {
+	metrics := []NodeMetrics{
+		{Class: "a", InDegree: 1, PageRank: 0.2},
+		{Class: "b", InDegree: 3, PageRank: 0.1},
+		{Class: "c", InDegree: 2, PageRank: 0.7},
+	}
+
+	tests := []struct {
+		name    string
+		by      string
+		n       int
+		want    []string
+		wantErr bool
+	}{
+		{"in", "in", 2, []string{"b", "c"}, false},
+		{"pagerank", "pagerank", 0, []string{"c", "a", "b"}, false},
+		{"more than available", "in", 10, []string{"b", "c", "a"}, false},
+
+		{"unknown key", "whatever", 1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TopNodeMetrics(metrics, tt.by, tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TopNodeMetrics() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("TopNodeMetrics() returned %d, want %d", len(got), len(tt.want))
+			}
+			for i, m := range got {
+				if m.Class != tt.want[i] {
+					t.Errorf("TopNodeMetrics()[%d] = %v, want %v", i, m.Class, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package dependency
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+// htmlNode is a class as seen by the HTML report. Dependencies and
+// dependents are indices into the node list.
+type htmlNode struct {
+	Name         string `json:"name"`
+	Package      string `json:"package"`
+	Module       string `json:"module,omitempty"`
+	File         string `json:"file,omitempty"`
+	SCC          int    `json:"scc"`
+	Dependencies []int  `json:"deps"`
+	Dependents   []int  `json:"dependents"`
+}
+
+// htmlData is what the HTML report shows. Imports holds where each step of
+// the cycle at the same index is imported, or an empty string if that
+// isn't known.
+type htmlData struct {
+	Nodes   []htmlNode `json:"nodes"`
+	SCCs    [][]int    `json:"sccs"`
+	Cycles  [][]string `json:"cycles"`
+	Imports [][]string `json:"imports"`
+}
+
+// WriteHTML writes a self-contained, static HTML report of the graph. It
+// has a searchable class and package list, the dependencies and dependents
+// of each class, the dependency cycles, and a force-directed view of each
+// strongly connected component. It needs no network access to view.
+func (d *Dependency) WriteHTML(w io.Writer) error {
+	return htmlReport.Execute(w, d.htmlData())
+}
+
+func (d *Dependency) htmlData() htmlData {
+	nodes, _ := d.exportGraph()
+
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.Name] = i
+	}
+
+	data := htmlData{
+		Nodes:   make([]htmlNode, len(nodes)),
+		SCCs:    make([][]int, 0),
+		Cycles:  make([][]string, 0),
+		Imports: make([][]string, 0),
+	}
+
+	for i, n := range nodes {
+		hn := htmlNode{
+			Name:         n.Name,
+			Package:      n.Package,
+			Module:       n.Module,
+			File:         n.File,
+			SCC:          n.SCC,
+			Dependencies: make([]int, 0),
+			Dependents:   make([]int, 0),
+		}
+
+		for _, dep := range d.Dependencies(n.Name) {
+			hn.Dependencies = append(hn.Dependencies, index[dep])
+		}
+
+		for _, dep := range d.Dependents(n.Name) {
+			hn.Dependents = append(hn.Dependents, index[dep])
+		}
+
+		data.Nodes[i] = hn
+	}
+
+	for _, scc := range d.StronglyConnectedComponents() {
+		members := make([]int, len(scc))
+		for i, class := range scc {
+			members[i] = index[class]
+		}
+
+		data.SCCs = append(data.SCCs, members)
+	}
+
+	cycles, _ := d.CheckCyclicDependencies()
+	for _, cycle := range cycles {
+		classes := strings.Split(cycle, " -> ")
+
+		imports := make([]string, len(classes)-1)
+		for i := range imports {
+			imports[i] = d.importLocation(classes[i], classes[i+1])
+		}
+
+		data.Cycles = append(data.Cycles, classes)
+		data.Imports = append(data.Imports, imports)
+	}
+
+	return data
+}
+
+// importLocation returns where the first import of to in from is, or the
+// file of from if only that is known.
+func (d *Dependency) importLocation(from, to string) string {
+	if records := d.Provenance(from, to); len(records) != 0 {
+		return records[0].String()
+	}
+
+	if n, ok := d.Node(from); ok {
+		return n.File
+	}
+
+	return ""
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>depser report</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; height: 100vh; }
+#side { width: 30%; border-right: 1px solid #ccc; display: flex; flex-direction: column; }
+#side input { margin: 8px; padding: 4px; }
+#list { overflow-y: auto; flex: 1; margin: 0; padding: 0 8px; list-style: none; }
+#list li, .link { cursor: pointer; font-family: monospace; }
+#list li:hover, .link:hover { text-decoration: underline; }
+#list .pkg { color: #555; font-weight: bold; margin-top: 6px; }
+#main { flex: 1; overflow-y: auto; padding: 0 16px; }
+.cyclic { color: #c00; }
+svg { border: 1px solid #ccc; }
+</style>
+</head>
+<body>
+<div id="side">
+<input id="search" type="search" placeholder="Search classes and packages">
+<ul id="list"></ul>
+</div>
+<div id="main">
+<div id="details"><h2>Select a class</h2></div>
+<h2>Cycles (<span id="cycle-count"></span>)</h2>
+<div id="cycles"></div>
+<h2>Strongly connected components</h2>
+<select id="scc-select"></select>
+<div><svg id="graph" width="800" height="600"></svg></div>
+</div>
+<script>
+var data = {{.}};
+
+var list = document.getElementById("list");
+var details = document.getElementById("details");
+
+function el(tag, text, cls) {
+	var e = document.createElement(tag);
+	if (text) e.textContent = text;
+	if (cls) e.className = cls;
+	return e;
+}
+
+function nodeLink(i) {
+	var n = data.nodes[i];
+	var li = el("li", n.name, "link" + (n.scc >= 0 ? " cyclic" : ""));
+	li.onclick = function () { show(i); };
+	return li;
+}
+
+function renderList(query) {
+	query = query.toLowerCase();
+	list.innerHTML = "";
+	var pkg = null;
+	data.nodes.forEach(function (n, i) {
+		if (query && n.name.toLowerCase().indexOf(query) === -1) return;
+		if (n.package !== pkg) {
+			pkg = n.package;
+			list.appendChild(el("li", pkg || "(default package)", "pkg"));
+		}
+		list.appendChild(nodeLink(i));
+	});
+}
+
+function show(i) {
+	var n = data.nodes[i];
+	details.innerHTML = "";
+	details.appendChild(el("h2", n.name));
+	if (n.module) details.appendChild(el("p", "Module: " + n.module));
+	if (n.file) details.appendChild(el("p", "File: " + n.file));
+	if (n.scc >= 0) {
+		var p = el("p", "Part of strongly connected component #" + n.scc, "link cyclic");
+		p.onclick = function () { selectSCC(n.scc); };
+		details.appendChild(p);
+	}
+	[["Dependencies", n.deps], ["Dependents", n.dependents]].forEach(function (s) {
+		details.appendChild(el("h3", s[0] + " (" + s[1].length + ")"));
+		var ul = el("ul");
+		s[1].forEach(function (j) { ul.appendChild(nodeLink(j)); });
+		details.appendChild(ul);
+	});
+}
+
+function renderCycles() {
+	var box = document.getElementById("cycles");
+	document.getElementById("cycle-count").textContent = data.cycles.length;
+	data.cycles.forEach(function (cycle, i) {
+		var det = el("details");
+		det.appendChild(el("summary", cycle[0] + " (" + (cycle.length - 1) + " step(s))"));
+		var ol = el("ol");
+		cycle.forEach(function (c, j) {
+			var loc = data.imports[i][j];
+			ol.appendChild(el("li", loc ? c + " (" + loc + ")" : c));
+		});
+		det.appendChild(ol);
+		box.appendChild(det);
+	});
+}
+
+var select = document.getElementById("scc-select");
+
+function renderSCCOptions() {
+	data.sccs.forEach(function (scc, i) {
+		var o = el("option", "#" + i + ": " + data.nodes[scc[0]].name + " (" + scc.length + " classes)");
+		o.value = i;
+		select.appendChild(o);
+	});
+	select.onchange = function () { drawSCC(+select.value); };
+	if (data.sccs.length) drawSCC(0);
+}
+
+function selectSCC(i) {
+	select.value = i;
+	drawSCC(i);
+}
+
+function drawSCC(s) {
+	var svg = document.getElementById("graph");
+	var ns = "http://www.w3.org/2000/svg";
+	var W = +svg.getAttribute("width"), H = +svg.getAttribute("height");
+	svg.innerHTML = '<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0L10,5L0,10z"/></marker></defs>';
+
+	var members = data.sccs[s];
+	var local = {};
+	var pts = members.map(function (m, k) {
+		local[m] = k;
+		var a = 2 * Math.PI * k / members.length;
+		return { x: W / 2 + W / 3 * Math.cos(a), y: H / 2 + H / 3 * Math.sin(a), dx: 0, dy: 0 };
+	});
+	var edges = [];
+	members.forEach(function (m) {
+		data.nodes[m].deps.forEach(function (d) {
+			if (d in local) edges.push([local[m], local[d]]);
+		});
+	});
+
+	var k = Math.sqrt(W * H / members.length);
+	for (var iter = 0, t = W / 10; iter < 300; iter++, t *= 0.98) {
+		pts.forEach(function (p) { p.dx = 0; p.dy = 0; });
+		pts.forEach(function (p, i) {
+			pts.forEach(function (q, j) {
+				if (i === j) return;
+				var dx = p.x - q.x, dy = p.y - q.y, dist = Math.max(Math.sqrt(dx * dx + dy * dy), 0.01);
+				p.dx += dx / dist * k * k / dist;
+				p.dy += dy / dist * k * k / dist;
+			});
+		});
+		edges.forEach(function (e) {
+			var p = pts[e[0]], q = pts[e[1]];
+			var dx = p.x - q.x, dy = p.y - q.y, dist = Math.max(Math.sqrt(dx * dx + dy * dy), 0.01);
+			var f = dist * dist / k;
+			p.dx -= dx / dist * f; p.dy -= dy / dist * f;
+			q.dx += dx / dist * f; q.dy += dy / dist * f;
+		});
+		pts.forEach(function (p) {
+			var len = Math.max(Math.sqrt(p.dx * p.dx + p.dy * p.dy), 0.01);
+			p.x = Math.min(W - 20, Math.max(20, p.x + p.dx / len * Math.min(len, t)));
+			p.y = Math.min(H - 20, Math.max(20, p.y + p.dy / len * Math.min(len, t)));
+		});
+	}
+
+	edges.forEach(function (e) {
+		var p = pts[e[0]], q = pts[e[1]];
+		var dx = q.x - p.x, dy = q.y - p.y, dist = Math.max(Math.sqrt(dx * dx + dy * dy), 0.01);
+		var line = document.createElementNS(ns, "line");
+		line.setAttribute("x1", p.x); line.setAttribute("y1", p.y);
+		line.setAttribute("x2", q.x - dx / dist * 6); line.setAttribute("y2", q.y - dy / dist * 6);
+		line.setAttribute("stroke", "#c00");
+		line.setAttribute("marker-end", "url(#arrow)");
+		svg.appendChild(line);
+	});
+	members.forEach(function (m, i) {
+		var c = document.createElementNS(ns, "circle");
+		c.setAttribute("cx", pts[i].x); c.setAttribute("cy", pts[i].y); c.setAttribute("r", 5);
+		c.style.cursor = "pointer";
+		c.onclick = function () { show(m); };
+		var label = document.createElementNS(ns, "text");
+		label.setAttribute("x", pts[i].x + 7); label.setAttribute("y", pts[i].y - 7);
+		label.setAttribute("font-size", "11");
+		label.textContent = data.nodes[m].name.split(".").pop();
+		var title = document.createElementNS(ns, "title");
+		title.textContent = data.nodes[m].name;
+		c.appendChild(title);
+		svg.appendChild(c);
+		svg.appendChild(label);
+	});
+}
+
+document.getElementById("search").oninput = function (e) { renderList(e.target.value); };
+renderList("");
+renderCycles();
+renderSCCOptions();
+</script>
+</body>
+</html>
+`))
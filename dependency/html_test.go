@@ -0,0 +1,73 @@
+package dependency
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDependency_WriteHTML(t *testing.T) {
+	d := testGraph()
+	d.Add("com.a.A", "com.evil.</script><script>alert(1)</script>")
+
+	var buf bytes.Buffer
+	if err := d.WriteHTML(&buf); err != nil {
+		t.Fatalf("Dependency.WriteHTML() error = %v", err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{`"name":"com.a.A"`, `"cycles":[[`, `"sccs":[[0,1]]`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dependency.WriteHTML() output is missing %q", want)
+		}
+	}
+
+	if strings.Contains(out, "<script>alert(1)") {
+		t.Errorf("Dependency.WriteHTML() did not escape class names")
+	}
+
+	for _, remote := range []string{"http://", "https://"} {
+		if strings.Contains(strings.Replace(out, "http://www.w3.org/2000/svg", "", -1), remote) {
+			t.Errorf("Dependency.WriteHTML() output references remote resources")
+		}
+	}
+}
+
+func TestDependency_htmlData(t *testing.T) {
+	data := testGraph().htmlData()
+
+	if len(data.Nodes) != 4 {
+		t.Fatalf("got %d nodes, want 4", len(data.Nodes))
+	}
+
+	a := data.Nodes[0]
+	if a.Name != "com.a.A" || len(a.Dependencies) != 1 || a.Dependencies[0] != 1 || len(a.Dependents) != 1 || a.SCC != 0 {
+		t.Errorf("unexpected node: %+v", a)
+	}
+
+	if len(data.Cycles) != 1 || len(data.Cycles[0]) != 3 {
+		t.Errorf("unexpected cycles: %v", data.Cycles)
+	}
+}
+
+func TestDependency_htmlData_imports(t *testing.T) {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "a.A", File: "A.java"})
+	d.Add("a.A", "b.B")
+	d.Add("b.B", "a.A")
+	d.AddProvenance("b.B", "a.A", Provenance{File: "B.java", Line: 3, Column: 1})
+
+	data := d.htmlData()
+
+	if len(data.Imports) != 1 {
+		t.Fatalf("got imports of %d cycles, want 1", len(data.Imports))
+	}
+
+	want := map[string]string{"a.A": "A.java", "b.B": "B.java:3:1"}
+	for i, loc := range data.Imports[0] {
+		if from := data.Cycles[0][i]; loc != want[from] {
+			t.Errorf("import of %s = %q, want %q", from, loc, want[from])
+		}
+	}
+}
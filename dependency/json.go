@@ -0,0 +1,318 @@
+package dependency
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SchemaVersion is the version of the JSON document written by WriteJSON
+// and WriteJSONLines.
+const SchemaVersion = 1
+
+// EdgeKindImport is the kind of the dependencies created by import
+// statements.
+const EdgeKindImport = "import"
+
+// jsonGraph is the document written by WriteJSON.
+type jsonGraph struct {
+	Version     int        `json:"version"`
+	AllowCycles bool       `json:"allowCycles"`
+	Nodes       []jsonNode `json:"nodes"`
+	Edges       []jsonEdge `json:"edges"`
+}
+
+type jsonNode struct {
+	Name      string `json:"name"`
+	Package   string `json:"package"`
+	Declared  bool   `json:"declared"`
+	File      string `json:"file,omitempty"`
+	Module    string `json:"module,omitempty"`
+	SourceSet string `json:"sourceSet,omitempty"`
+	Abstract  bool   `json:"abstract,omitempty"`
+}
+
+type jsonEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Kind       string `json:"kind"`
+	Sites      int    `json:"sites"`
+	Suppressed bool   `json:"suppressed,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+
+	Provenance []Provenance `json:"provenance,omitempty"`
+}
+
+// jsonLine is a single line written by WriteJSONLines. Type is one of
+// graph, node or edge, and decides which of the other fields are set.
+type jsonLine struct {
+	Type string `json:"type"`
+
+	*jsonHeader
+	*jsonNode
+	*jsonEdge
+}
+
+type jsonHeader struct {
+	Version     int  `json:"version"`
+	AllowCycles bool `json:"allowCycles"`
+}
+
+// WriteJSON writes the whole graph as a single JSON document:
+//
+//	{
+//	  "version": 1,
+//	  "allowCycles": true,
+//	  "nodes": [
+//	    {"name": "com.foo.Foo", "package": "com.foo", "declared": true,
+//	     "file": "src/main/java/com/foo/Foo.java", "module": "foo",
+//	     "sourceSet": "main", "abstract": false}
+//	  ],
+//	  "edges": [
+//	    {"from": "com.foo.Foo", "to": "com.bar.Bar", "kind": "import", "sites": 1,
+//	     "suppressed": true, "reason": "required by the framework",
+//	     "provenance": [{"file": "src/main/java/com/foo/Foo.java", "line": 3,
+//	       "column": 1, "statement": "import com.bar.Bar;", "kind": "import"}]}
+//	  ]
+//	}
+//
+// Nodes that are only imported, but not declared in the scanned sources
+// have declared set to false and no file, module or source set. Edges that
+// are not suppressed have neither suppressed nor reason set, and edges of
+// unknown provenance have no provenance.
+func (d *Dependency) WriteJSON(w io.Writer) error {
+	doc := jsonGraph{
+		Version:     SchemaVersion,
+		AllowCycles: d.allowCycles,
+		Nodes:       make([]jsonNode, 0),
+		Edges:       make([]jsonEdge, 0),
+	}
+
+	d.eachRecord(func(n *jsonNode, e *jsonEdge) {
+		if n != nil {
+			doc.Nodes = append(doc.Nodes, *n)
+		} else {
+			doc.Edges = append(doc.Edges, *e)
+		}
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
+
+// WriteJSONLines writes the graph as JSON Lines: a graph header first,
+// followed by one line per node, then one line per edge. Each line
+// carries the same fields as the matching part of the WriteJSON
+// document, plus a type.
+func (d *Dependency) WriteJSONLines(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	err := enc.Encode(jsonLine{
+		Type:       "graph",
+		jsonHeader: &jsonHeader{Version: SchemaVersion, AllowCycles: d.allowCycles},
+	})
+	if err != nil {
+		return err
+	}
+
+	d.eachRecord(func(n *jsonNode, e *jsonEdge) {
+		if err != nil {
+			return
+		}
+
+		if n != nil {
+			err = enc.Encode(jsonLine{Type: "node", jsonNode: n})
+		} else {
+			err = enc.Encode(jsonLine{Type: "edge", jsonEdge: e})
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// eachRecord calls fn with every node first, then with every edge,
+// both in alphabetical order.
+func (d *Dependency) eachRecord(fn func(n *jsonNode, e *jsonEdge)) {
+	classes := d.Classes()
+
+	for _, class := range classes {
+		n := jsonNode{Name: class, Package: PackageOf(class)}
+
+		if node, ok := d.Node(class); ok {
+			n.Declared = true
+			n.File = node.File
+			n.Module = node.Module
+			n.SourceSet = node.SourceSet
+			n.Abstract = node.Abstract
+		}
+
+		fn(&n, nil)
+	}
+
+	for _, from := range classes {
+		for _, to := range d.Dependencies(from) {
+			e := jsonEdge{From: from, To: to, Kind: EdgeKindImport, Sites: d.ImportSites(from, to)}
+			e.Reason, e.Suppressed = d.Suppressed(from, to)
+			e.Provenance = d.Provenance(from, to)
+
+			fn(nil, &e)
+		}
+	}
+}
+
+// ReadJSON loads a graph written by WriteJSON.
+func ReadJSON(r io.Reader) (*Dependency, error) {
+	const op = "ReadJSON"
+
+	var doc jsonGraph
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%v: decode: %v", op, err)
+	}
+
+	if doc.Version != SchemaVersion {
+		return nil, fmt.Errorf("%v: unsupported version: %d", op, doc.Version)
+	}
+
+	// Edges are loaded with cycles allowed, as the original graph may
+	// have had them. The setting is restored once everything is loaded.
+	d := NewWithCycles(true)
+
+	for _, n := range doc.Nodes {
+		if err := d.loadNode(n); err != nil {
+			return nil, fmt.Errorf("%v: %v", op, err)
+		}
+	}
+
+	for _, e := range doc.Edges {
+		if err := d.loadEdge(e); err != nil {
+			return nil, fmt.Errorf("%v: %v", op, err)
+		}
+	}
+
+	d.allowCycles = doc.AllowCycles
+
+	return d, nil
+}
+
+// ReadJSONLines loads a graph written by WriteJSONLines.
+func ReadJSONLines(r io.Reader) (*Dependency, error) {
+	const op = "ReadJSONLines"
+
+	dec := json.NewDecoder(r)
+
+	var (
+		d    *Dependency
+		head jsonHeader
+	)
+
+	for line := 1; dec.More(); line++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("%v: decode line %d: %v", op, line, err)
+		}
+
+		var rec struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return nil, fmt.Errorf("%v: decode line %d: %v", op, line, err)
+		}
+
+		if line == 1 {
+			if rec.Type != "graph" {
+				return nil, fmt.Errorf("%v: first line is not a graph header", op)
+			}
+
+			if err := json.Unmarshal(raw, &head); err != nil {
+				return nil, fmt.Errorf("%v: decode header: %v", op, err)
+			}
+
+			if head.Version != SchemaVersion {
+				return nil, fmt.Errorf("%v: unsupported version: %d", op, head.Version)
+			}
+
+			d = NewWithCycles(true)
+			continue
+		}
+
+		var err error
+		switch rec.Type {
+		case "node":
+			var n jsonNode
+			if err = json.Unmarshal(raw, &n); err == nil {
+				err = d.loadNode(n)
+			}
+		case "edge":
+			var e jsonEdge
+			if err = json.Unmarshal(raw, &e); err == nil {
+				err = d.loadEdge(e)
+			}
+		default:
+			err = fmt.Errorf("unknown record type: %q", rec.Type)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%v: line %d: %v", op, line, err)
+		}
+	}
+
+	if d == nil {
+		return nil, fmt.Errorf("%v: missing graph header", op)
+	}
+
+	d.allowCycles = head.AllowCycles
+
+	return d, nil
+}
+
+func (d *Dependency) loadNode(n jsonNode) error {
+	if !n.Declared {
+		return nil
+	}
+
+	return d.SetNode(Node{
+		Name:      n.Name,
+		File:      n.File,
+		Module:    n.Module,
+		SourceSet: n.SourceSet,
+		Abstract:  n.Abstract,
+	})
+}
+
+func (d *Dependency) loadEdge(e jsonEdge) error {
+	if e.Kind != EdgeKindImport {
+		return fmt.Errorf("unknown edge kind: %q", e.Kind)
+	}
+
+	if e.Sites < 1 {
+		e.Sites = 1
+	}
+
+	if e.Suppressed {
+		if err := d.Suppress(e.From, e.To, e.Reason); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < e.Sites; i++ {
+		if err := d.Add(e.From, e.To); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range e.Provenance {
+		if err := d.AddProvenance(e.From, e.To, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package dependency
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testGraph() *Dependency {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "com.a.A", File: "a/src/main/java/com/a/A.java", Module: "a", SourceSet: "main"})
+	d.SetNode(Node{Name: "com.b.B", File: "b/src/test/java/com/b/B.java", Module: "b", SourceSet: "test", Abstract: true})
+	d.SetNode(Node{Name: "com.c.Lonely"})
+	d.Add("com.a.A", "com.b.B")
+	d.Add("com.a.A", "com.b.B")
+	d.Add("com.b.B", "com.a.A")
+	d.Add("com.b.B", "java.util.List")
+	d.Suppress("com.b.B", "java.util.List", "collections are fine")
+
+	return d
+}
+
+func assertSameGraph(t *testing.T, got, want *Dependency) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got.Classes(), want.Classes()) {
+		t.Fatalf("classes = %v, want %v", got.Classes(), want.Classes())
+	}
+
+	for _, class := range want.Classes() {
+		gotNode, gotOK := got.Node(class)
+		wantNode, wantOK := want.Node(class)
+		if gotNode != wantNode || gotOK != wantOK {
+			t.Errorf("node %s = %+v, want %+v", class, gotNode, wantNode)
+		}
+
+		if !reflect.DeepEqual(got.Dependencies(class), want.Dependencies(class)) {
+			t.Errorf("dependencies of %s = %v, want %v", class, got.Dependencies(class), want.Dependencies(class))
+		}
+
+		for _, dep := range want.Dependencies(class) {
+			gotReason, gotOK := got.Suppressed(class, dep)
+			wantReason, wantOK := want.Suppressed(class, dep)
+			if gotReason != wantReason || gotOK != wantOK {
+				t.Errorf("suppression of %s -> %s = %q, %v, want %q, %v", class, dep, gotReason, gotOK, wantReason, wantOK)
+			}
+
+			if got.ImportSites(class, dep) != want.ImportSites(class, dep) {
+				t.Errorf("sites of %s -> %s = %d, want %d", class, dep, got.ImportSites(class, dep), want.ImportSites(class, dep))
+			}
+		}
+	}
+
+	if got.allowCycles != want.allowCycles {
+		t.Errorf("allowCycles = %v, want %v", got.allowCycles, want.allowCycles)
+	}
+}
+
+func TestDependency_JSON(t *testing.T) {
+	want := testGraph()
+
+	var buf bytes.Buffer
+	if err := want.WriteJSON(&buf); err != nil {
+		t.Fatalf("Dependency.WriteJSON() error = %v", err)
+	}
+
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+
+	assertSameGraph(t, got, want)
+}
+
+func TestDependency_JSONLines(t *testing.T) {
+	want := testGraph()
+
+	var buf bytes.Buffer
+	if err := want.WriteJSONLines(&buf); err != nil {
+		t.Fatalf("Dependency.WriteJSONLines() error = %v", err)
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != 1+4+3 {
+		t.Errorf("Dependency.WriteJSONLines() wrote %d lines, want %d", lines, 8)
+	}
+
+	got, err := ReadJSONLines(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONLines() error = %v", err)
+	}
+
+	assertSameGraph(t, got, want)
+}
+
+func TestReadJSON_invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		read  func(string) (*Dependency, error)
+		input string
+	}{
+		{"json garbage", func(s string) (*Dependency, error) { return ReadJSON(strings.NewReader(s)) }, "{"},
+		{"json version", func(s string) (*Dependency, error) { return ReadJSON(strings.NewReader(s)) }, `{"version": 99}`},
+		{"json edge kind", func(s string) (*Dependency, error) { return ReadJSON(strings.NewReader(s)) },
+			`{"version": 1, "edges": [{"from": "a", "to": "b", "kind": "magic"}]}`},
+		{"lines no header", func(s string) (*Dependency, error) { return ReadJSONLines(strings.NewReader(s)) },
+			`{"type": "node", "name": "a"}`},
+		{"lines empty", func(s string) (*Dependency, error) { return ReadJSONLines(strings.NewReader(s)) }, ""},
+		{"lines unknown type", func(s string) (*Dependency, error) { return ReadJSONLines(strings.NewReader(s)) },
+			"{\"type\": \"graph\", \"version\": 1}\n{\"type\": \"what\"}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.read(tt.input); err == nil {
+				t.Errorf("expected error reading %q", tt.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package dependency
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// LibraryUsage tells how a third-party package is used by the internal
+// classes. Classes are the classes of the package that are used, Users
+// the internal classes using them, and Packages and Modules are where the
+// users are.
+type LibraryUsage struct {
+	Package  string   `json:"package"`
+	Classes  []string `json:"classes"`
+	Users    []string `json:"users"`
+	Packages []string `json:"packages"`
+	Modules  []string `json:"modules,omitempty"`
+}
+
+// LibrarySet lists the third-party packages used by an internal package or
+// module.
+type LibrarySet struct {
+	Name      string   `json:"name"`
+	Libraries []string `json:"libraries"`
+}
+
+// Libraries returns how the third-party packages are used, sorted by
+// package name. See LibraryPackage for how classes are grouped to
+// packages.
+func (d *Dependency) Libraries() []LibraryUsage {
+	type usage struct {
+		classes, users, packages, modules map[string]struct{}
+	}
+
+	libs := make(map[string]*usage)
+
+	d.eachLibraryUse(func(class, user string, n Node) {
+		pkg := LibraryPackage(class)
+
+		u, ok := libs[pkg]
+		if !ok {
+			u = &usage{
+				classes:  make(map[string]struct{}),
+				users:    make(map[string]struct{}),
+				packages: make(map[string]struct{}),
+				modules:  make(map[string]struct{}),
+			}
+			libs[pkg] = u
+		}
+
+		u.classes[class] = struct{}{}
+		u.users[user] = struct{}{}
+		u.packages[PackageOf(user)] = struct{}{}
+
+		if n.Module != "" {
+			u.modules[n.Module] = struct{}{}
+		}
+	})
+
+	usages := make([]LibraryUsage, 0, len(libs))
+	for pkg, u := range libs {
+		usages = append(usages, LibraryUsage{
+			Package:  pkg,
+			Classes:  sortedKeys(u.classes),
+			Users:    sortedKeys(u.users),
+			Packages: sortedKeys(u.packages),
+			Modules:  sortedKeys(u.modules),
+		})
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Package < usages[j].Package
+	})
+
+	return usages
+}
+
+// LibrariesByPackage returns the third-party packages used by each internal
+// package, sorted by package name.
+func (d *Dependency) LibrariesByPackage() []LibrarySet {
+	return d.librariesBy(func(user string, n Node) string {
+		return PackageOf(user)
+	})
+}
+
+// LibrariesByModule returns the third-party packages used by each module,
+// sorted by module name. Classes outside of modules are left out.
+func (d *Dependency) LibrariesByModule() []LibrarySet {
+	return d.librariesBy(func(user string, n Node) string {
+		return n.Module
+	})
+}
+
+// RarelyUsed returns the libraries used by at most max classes, which are
+// candidates for removal.
+func RarelyUsed(usages []LibraryUsage, max int) []LibraryUsage {
+	var rare []LibraryUsage
+	for _, u := range usages {
+		if len(u.Users) <= max {
+			rare = append(rare, u)
+		}
+	}
+
+	return rare
+}
+
+// LibraryPackage returns the package of an imported third-party class, as
+// told by importedPackage.
+func LibraryPackage(class string) string {
+	if pkg, ok := importedPackage(class); ok {
+		return pkg
+	}
+
+	return PackageOf(class)
+}
+
+// eachLibraryUse calls fn for each dependency of an internal class on a
+// third-party one, with the node of the internal class.
+func (d *Dependency) eachLibraryUse(fn func(class, user string, n Node)) {
+	for _, class := range d.Classes() {
+		if d.OriginOf(class) != ThirdParty {
+			continue
+		}
+
+		for _, user := range d.Dependents(class) {
+			if n, ok := d.Node(user); ok {
+				fn(class, user, n)
+			}
+		}
+	}
+}
+
+func (d *Dependency) librariesBy(key func(user string, n Node) string) []LibrarySet {
+	libs := make(map[string]map[string]struct{})
+
+	d.eachLibraryUse(func(class, user string, n Node) {
+		name := key(user, n)
+		if name == "" {
+			return
+		}
+
+		if libs[name] == nil {
+			libs[name] = make(map[string]struct{})
+		}
+
+		libs[name][LibraryPackage(class)] = struct{}{}
+	})
+
+	sets := make([]LibrarySet, 0, len(libs))
+	for name, set := range libs {
+		sets = append(sets, LibrarySet{Name: name, Libraries: sortedKeys(set)})
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Name < sets[j].Name
+	})
+
+	return sets
+}
+
+// sortedKeys returns the elements of set in alphabetical order, or nil if
+// it is empty.
+func sortedKeys(set map[string]struct{}) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// WriteLibrariesTable writes the usages as an aligned, human readable
+// table.
+func WriteLibrariesTable(w io.Writer, usages []LibraryUsage) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "LIBRARY\tCLASSES\tUSERS\tPACKAGES\tMODULES")
+	for _, u := range usages {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n",
+			u.Package, len(u.Classes), len(u.Users), len(u.Packages), strings.Join(u.Modules, ", "))
+	}
+
+	return tw.Flush()
+}
+
+// WriteLibrariesCSV writes the usages as CSV with a header row. Rare
+// tells whether a library is used by at most rare classes.
+func WriteLibrariesCSV(w io.Writer, usages []LibraryUsage, rare int) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"library", "classes", "users", "packages", "modules", "rare"})
+	for _, u := range usages {
+		cw.Write([]string{
+			u.Package,
+			strconv.Itoa(len(u.Classes)),
+			strconv.Itoa(len(u.Users)),
+			strconv.Itoa(len(u.Packages)),
+			strings.Join(u.Modules, " "),
+			strconv.FormatBool(len(u.Users) <= rare),
+		})
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,137 @@
+package dependency
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func librariesGraph() *Dependency {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "com.acme.web.Controller", Module: "web"})
+	d.SetNode(Node{Name: "com.acme.web.View", Module: "web"})
+	d.SetNode(Node{Name: "com.acme.core.Service", Module: "core"})
+	d.Add("com.acme.web.Controller", "org.slf4j.Logger")
+	d.Add("com.acme.web.Controller", "com.acme.core.Service")
+	d.Add("com.acme.web.View", "org.slf4j.LoggerFactory")
+	d.Add("com.acme.web.View", "java.util.List")
+	d.Add("com.acme.core.Service", "org.slf4j.Logger")
+	d.Add("com.acme.core.Service", "static org.apache.commons.lang3.StringUtils.isBlank")
+
+	return d
+}
+
+func TestDependency_Libraries(t *testing.T) {
+	want := []LibraryUsage{
+		{
+			Package:  "org.apache.commons.lang3",
+			Classes:  []string{"static org.apache.commons.lang3.StringUtils.isBlank"},
+			Users:    []string{"com.acme.core.Service"},
+			Packages: []string{"com.acme.core"},
+			Modules:  []string{"core"},
+		},
+		{
+			Package:  "org.slf4j",
+			Classes:  []string{"org.slf4j.Logger", "org.slf4j.LoggerFactory"},
+			Users:    []string{"com.acme.core.Service", "com.acme.web.Controller", "com.acme.web.View"},
+			Packages: []string{"com.acme.core", "com.acme.web"},
+			Modules:  []string{"core", "web"},
+		},
+	}
+
+	if got := librariesGraph().Libraries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Libraries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDependency_LibrariesBy(t *testing.T) {
+	d := librariesGraph()
+
+	wantPackages := []LibrarySet{
+		{Name: "com.acme.core", Libraries: []string{"org.apache.commons.lang3", "org.slf4j"}},
+		{Name: "com.acme.web", Libraries: []string{"org.slf4j"}},
+	}
+	if got := d.LibrariesByPackage(); !reflect.DeepEqual(got, wantPackages) {
+		t.Errorf("LibrariesByPackage() = %+v, want %+v", got, wantPackages)
+	}
+
+	wantModules := []LibrarySet{
+		{Name: "core", Libraries: []string{"org.apache.commons.lang3", "org.slf4j"}},
+		{Name: "web", Libraries: []string{"org.slf4j"}},
+	}
+	if got := d.LibrariesByModule(); !reflect.DeepEqual(got, wantModules) {
+		t.Errorf("LibrariesByModule() = %+v, want %+v", got, wantModules)
+	}
+}
+
+func TestRarelyUsed(t *testing.T) {
+	usages := librariesGraph().Libraries()
+
+	tests := []struct {
+		max  int
+		want []string
+	}{
+		{0, nil},
+		{1, []string{"org.apache.commons.lang3"}},
+		{3, []string{"org.apache.commons.lang3", "org.slf4j"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, u := range RarelyUsed(usages, tt.max) {
+			got = append(got, u.Package)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RarelyUsed(%d) = %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestLibraryPackage(t *testing.T) {
+	tests := []struct {
+		class string
+		want  string
+	}{
+		{"org.slf4j.Logger", "org.slf4j"},
+		{"com.google.common.collect.ImmutableMap.Builder", "com.google.common.collect"},
+		{"static org.junit.Assert.assertEquals", "org.junit"},
+		{"org.junit.jupiter.api.*", "org.junit.jupiter.api"},
+		{"static org.hamcrest.Matchers.*", "org.hamcrest"},
+		{"lowercase.only.name", "lowercase.only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			if got := LibraryPackage(tt.class); got != tt.want {
+				t.Errorf("LibraryPackage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLibraries(t *testing.T) {
+	usages := librariesGraph().Libraries()
+
+	var table bytes.Buffer
+	if err := WriteLibrariesTable(&table, usages); err != nil {
+		t.Fatalf("WriteLibrariesTable() error = %v", err)
+	}
+
+	for _, want := range []string{"LIBRARY", "org.slf4j", "core, web"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("WriteLibrariesTable() = %q, want it to contain %q", table.String(), want)
+		}
+	}
+
+	var csv bytes.Buffer
+	if err := WriteLibrariesCSV(&csv, usages, 1); err != nil {
+		t.Fatalf("WriteLibrariesCSV() error = %v", err)
+	}
+
+	want := "library,classes,users,packages,modules,rare\n" +
+		"org.apache.commons.lang3,1,1,1,core,true\n" +
+		"org.slf4j,2,3,2,core web,false\n"
+	if csv.String() != want {
+		t.Errorf("WriteLibrariesCSV() = %q, want %q", csv.String(), want)
+	}
+}
@@ -0,0 +1,156 @@
+package dependency
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// PackageMetrics holds Robert Martin's package metrics for a single
+// package.
+//
+// Ca (afferent coupling) is the number of classes outside the package
+// that depend on classes inside it. Ce (efferent coupling) is the number
+// of classes inside the package that depend on classes outside of it.
+type PackageMetrics struct {
+	Package         string  `json:"package"`
+	Classes         int     `json:"classes"`
+	AbstractClasses int     `json:"abstractClasses"`
+	Ca              int     `json:"ca"`
+	Ce              int     `json:"ce"`
+	Instability     float64 `json:"instability"`
+	Abstractness    float64 `json:"abstractness"`
+	Distance        float64 `json:"distance"`
+}
+
+// PackageMetrics aggregates the class level dependencies to packages and
+// calculates the metrics for each of them, sorted by package name.
+func (d *Dependency) PackageMetrics() []PackageMetrics {
+	type counts struct {
+		classes, abstracts int
+		ca, ce             map[string]struct{}
+	}
+
+	pkgs := make(map[string]*counts)
+	get := func(pkg string) *counts {
+		c, ok := pkgs[pkg]
+		if !ok {
+			c = &counts{ca: make(map[string]struct{}), ce: make(map[string]struct{})}
+			pkgs[pkg] = c
+		}
+
+		return c
+	}
+
+	classes := d.Classes()
+	for _, class := range classes {
+		c := get(PackageOf(class))
+		c.classes++
+
+		if n, ok := d.Node(class); ok && n.Abstract {
+			c.abstracts++
+		}
+	}
+
+	for _, depender := range classes {
+		from := PackageOf(depender)
+
+		for _, dependent := range d.Dependencies(depender) {
+			to := PackageOf(dependent)
+			if from == to {
+				continue
+			}
+
+			get(from).ce[depender] = struct{}{}
+			get(to).ca[depender] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for pkg := range pkgs {
+		names = append(names, pkg)
+	}
+
+	sort.Strings(names)
+
+	metrics := make([]PackageMetrics, 0, len(pkgs))
+	for _, pkg := range names {
+		c := pkgs[pkg]
+
+		m := PackageMetrics{
+			Package:         pkg,
+			Classes:         c.classes,
+			AbstractClasses: c.abstracts,
+			Ca:              len(c.ca),
+			Ce:              len(c.ce),
+		}
+
+		if m.Ca+m.Ce != 0 {
+			m.Instability = float64(m.Ce) / float64(m.Ca+m.Ce)
+		}
+
+		if m.Classes != 0 {
+			m.Abstractness = float64(m.AbstractClasses) / float64(m.Classes)
+		}
+
+		m.Distance = math.Abs(m.Abstractness + m.Instability - 1)
+
+		metrics = append(metrics, m)
+	}
+
+	return metrics
+}
+
+// WritePackageMetricsTable writes the metrics as an aligned, human
+// readable table.
+func WritePackageMetricsTable(w io.Writer, metrics []PackageMetrics) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "PACKAGE\tCLASSES\tABSTRACT\tCA\tCE\tI\tA\tD")
+	for _, m := range metrics {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\n",
+			m.Package, m.Classes, m.AbstractClasses, m.Ca, m.Ce, m.Instability, m.Abstractness, m.Distance)
+	}
+
+	return tw.Flush()
+}
+
+// WritePackageMetricsCSV writes the metrics as CSV with a header row.
+func WritePackageMetricsCSV(w io.Writer, metrics []PackageMetrics) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"package", "classes", "abstractClasses", "ca", "ce", "instability", "abstractness", "distance"})
+	for _, m := range metrics {
+		cw.Write([]string{
+			m.Package,
+			strconv.Itoa(m.Classes),
+			strconv.Itoa(m.AbstractClasses),
+			strconv.Itoa(m.Ca),
+			strconv.Itoa(m.Ce),
+			formatFloat(m.Instability),
+			formatFloat(m.Abstractness),
+			formatFloat(m.Distance),
+		})
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// WritePackageMetricsJSON writes the metrics as an indented JSON array.
+func WritePackageMetricsJSON(w io.Writer, metrics []PackageMetrics) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(metrics)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
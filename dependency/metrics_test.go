@@ -0,0 +1,83 @@
+package dependency
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDependency_PackageMetrics(t *testing.T) {
+	d := New()
+
+	for _, n := range []Node{
+		{Name: "app.Main"},
+		{Name: "app.Helper"},
+		{Name: "api.Service", Abstract: true},
+		{Name: "api.Model"},
+		{Name: "impl.ServiceImpl"},
+	} {
+		d.SetNode(n)
+	}
+
+	for _, edge := range [][2]string{
+		{"app.Main", "api.Service"},
+		{"app.Main", "app.Helper"},
+		{"app.Helper", "api.Model"},
+		{"impl.ServiceImpl", "api.Service"},
+		{"impl.ServiceImpl", "java.util.List"},
+	} {
+		if err := d.Add(edge[0], edge[1]); err != nil {
+			t.Fatalf("Dependency.Add() error = %v", err)
+		}
+	}
+
+	want := map[string]PackageMetrics{
+		"api":       {Package: "api", Classes: 2, AbstractClasses: 1, Ca: 3, Ce: 0, Instability: 0, Abstractness: 0.5, Distance: 0.5},
+		"app":       {Package: "app", Classes: 2, AbstractClasses: 0, Ca: 0, Ce: 2, Instability: 1, Abstractness: 0, Distance: 0},
+		"impl":      {Package: "impl", Classes: 1, AbstractClasses: 0, Ca: 0, Ce: 1, Instability: 1, Abstractness: 0, Distance: 0},
+		"java.util": {Package: "java.util", Classes: 1, AbstractClasses: 0, Ca: 1, Ce: 0, Instability: 0, Abstractness: 0, Distance: 1},
+	}
+
+	got := d.PackageMetrics()
+	if len(got) != len(want) {
+		t.Fatalf("Dependency.PackageMetrics() returned %d packages, want %d", len(got), len(want))
+	}
+
+	for i, m := range got {
+		if i > 0 && got[i-1].Package > m.Package {
+			t.Errorf("Dependency.PackageMetrics() not sorted: %q before %q", got[i-1].Package, m.Package)
+		}
+
+		if m != want[m.Package] {
+			t.Errorf("Dependency.PackageMetrics() = %+v, want %+v", m, want[m.Package])
+		}
+	}
+}
+
+func TestWritePackageMetrics(t *testing.T) {
+	metrics := []PackageMetrics{
+		{Package: "api", Classes: 2, AbstractClasses: 1, Ca: 3, Abstractness: 0.5, Distance: 0.5},
+	}
+
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer) error
+		want  string
+	}{
+		{"table", func(b *bytes.Buffer) error { return WritePackageMetricsTable(b, metrics) }, "api      2        1         3   0   0.00  0.50  0.50"},
+		{"csv", func(b *bytes.Buffer) error { return WritePackageMetricsCSV(b, metrics) }, "api,2,1,3,0,0.0000,0.5000,0.5000"},
+		{"json", func(b *bytes.Buffer) error { return WritePackageMetricsJSON(b, metrics) }, `"abstractness": 0.5`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output %q does not contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
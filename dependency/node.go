@@ -0,0 +1,106 @@
+package dependency
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Node holds what is known about a class that is declared in the
+// scanned sources. Classes that only show up as imports don't have
+// a Node.
+type Node struct {
+	Name      string
+	File      string
+	Module    string
+	SourceSet string
+	Abstract  bool
+}
+
+// SetNode records the details of a declared class, overwriting any
+// previously recorded details of the same class.
+func (d *Dependency) SetNode(n Node) error {
+	if n.Name == "" {
+		return fmt.Errorf("empty node name")
+	}
+
+	d.nodeRW.Lock()
+	d.nodes[n.Name] = n
+	d.nodeRW.Unlock()
+
+	return nil
+}
+
+// Node returns the details of a declared class. The boolean is false
+// if the class was not declared in the scanned sources.
+func (d *Dependency) Node(name string) (Node, bool) {
+	d.nodeRW.RLock()
+	n, ok := d.nodes[name]
+	d.nodeRW.RUnlock()
+
+	return n, ok
+}
+
+// Classes returns every class in the graph in alphabetical order,
+// regardless of whether it was declared or only imported.
+func (d *Dependency) Classes() []string {
+	set := make(map[string]struct{})
+
+	d.depRW.RLock()
+	for depender, dependents := range d.deps {
+		set[depender] = struct{}{}
+		for _, dep := range dependents {
+			set[dep] = struct{}{}
+		}
+	}
+	d.depRW.RUnlock()
+
+	d.nodeRW.RLock()
+	for name := range d.nodes {
+		set[name] = struct{}{}
+	}
+	d.nodeRW.RUnlock()
+
+	classes := make([]string, 0, len(set))
+	for class := range set {
+		classes = append(classes, class)
+	}
+
+	sort.Strings(classes)
+
+	return classes
+}
+
+// Dependencies returns the classes that depender directly depends on,
+// in alphabetical order.
+func (d *Dependency) Dependencies(depender string) []string {
+	d.depRW.RLock()
+	deps := append([]string(nil), d.deps[depender]...)
+	d.depRW.RUnlock()
+
+	sort.Strings(deps)
+
+	return deps
+}
+
+// Dependents returns the classes that directly depend on dependent,
+// in alphabetical order.
+func (d *Dependency) Dependents(dependent string) []string {
+	d.visRW.RLock()
+	stalkers := append([]string(nil), d.visibilities[dependent]...)
+	d.visRW.RUnlock()
+
+	sort.Strings(stalkers)
+
+	return stalkers
+}
+
+// PackageOf returns the package part of a fully qualified class name.
+func PackageOf(class string) string {
+	ind := strings.LastIndex(class, ".")
+	if ind == -1 {
+		return ""
+	}
+
+	return class[:ind]
+}
@@ -0,0 +1,66 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependency_SetNode(t *testing.T) {
+	d := New()
+
+	if err := d.SetNode(Node{}); err == nil {
+		t.Errorf("Dependency.SetNode() expected error for empty name")
+	}
+
+	want := Node{Name: "com.example.Test", File: "Test.java", Abstract: true}
+	if err := d.SetNode(want); err != nil {
+		t.Fatalf("Dependency.SetNode() error = %v", err)
+	}
+
+	got, ok := d.Node(want.Name)
+	if !ok || got != want {
+		t.Errorf("Dependency.Node() = %+v, %v, want %+v, true", got, ok, want)
+	}
+
+	if _, ok := d.Node("com.example.Missing"); ok {
+		t.Errorf("Dependency.Node() found undeclared class")
+	}
+}
+
+func TestDependency_Classes(t *testing.T) {
+	d := New()
+	d.SetNode(Node{Name: "c"})
+	d.Add("b", "a")
+	d.Add("b", "d")
+
+	if got, want := d.Classes(), []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependency.Classes() = %v, want %v", got, want)
+	}
+
+	if got, want := d.Dependencies("b"), []string{"a", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependency.Dependencies() = %v, want %v", got, want)
+	}
+
+	if got, want := d.Dependents("a"), []string{"b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependency.Dependents() = %v, want %v", got, want)
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		class string
+		want  string
+	}{
+		{"normal", "com.example.Test", "com.example"},
+		{"short", "a.B", "a"},
+		{"default package", "Test", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PackageOf(tt.class); got != tt.want {
+				t.Errorf("PackageOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package dependency
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Origin tells where a class comes from.
+type Origin int
+
+// Origins of classes. Internal classes are the ones declared in the scanned
+// sources, i.e. the ones that have a Node. The rest are external: JDK
+// classes, as told by IsJDK, and third-party ones.
+const (
+	Internal Origin = iota
+	JDK
+	ThirdParty
+)
+
+func (o Origin) String() string {
+	switch o {
+	case Internal:
+		return "internal"
+	case JDK:
+		return "jdk"
+	case ThirdParty:
+		return "third-party"
+	}
+
+	return fmt.Sprintf("Origin(%d)", int(o))
+}
+
+// jdkPrefixes are the packages shipped with the JDK. Only some of the javax
+// and com.sun packages are, others come from libraries like the Servlet API
+// or Jersey, so those are listed one by one.
+var jdkPrefixes = []string{
+	"java.", "jdk.", "sun.",
+	"javax.accessibility.", "javax.annotation.processing.", "javax.crypto.",
+	"javax.imageio.", "javax.lang.model.", "javax.management.", "javax.naming.",
+	"javax.net.", "javax.print.", "javax.rmi.ssl.", "javax.script.",
+	"javax.security.auth.", "javax.security.cert.", "javax.security.sasl.",
+	"javax.smartcardio.", "javax.sound.", "javax.sql.", "javax.swing.",
+	"javax.tools.", "javax.transaction.xa.",
+	"javax.xml.catalog.", "javax.xml.crypto.", "javax.xml.datatype.",
+	"javax.xml.namespace.", "javax.xml.parsers.", "javax.xml.stream.",
+	"javax.xml.transform.", "javax.xml.validation.", "javax.xml.xpath.",
+	"com.sun.crypto.provider.", "com.sun.java.", "com.sun.jdi.", "com.sun.jndi.",
+	"com.sun.management.", "com.sun.net.httpserver.", "com.sun.nio.",
+	"com.sun.security.", "com.sun.source.", "com.sun.tools.",
+	"org.w3c.dom.", "org.xml.sax.", "org.ietf.jgss.", "org.omg.",
+}
+
+// IsJDK returns true if the class, or package, belongs to the JDK. Static
+// imports are recognised as well.
+func IsJDK(class string) bool {
+	class = strings.TrimPrefix(class, "static ")
+
+	for _, prefix := range jdkPrefixes {
+		if strings.HasPrefix(class+".", prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OriginOf tells where class comes from.
+func (d *Dependency) OriginOf(class string) Origin {
+	if _, ok := d.Node(class); ok {
+		return Internal
+	}
+
+	if IsJDK(class) {
+		return JDK
+	}
+
+	return ThirdParty
+}
+
+// Drop removes the external classes of origin from the graph, along with
+// the dependencies on them. It returns how many classes were removed.
+func (d *Dependency) Drop(origin Origin) (int, error) {
+	classes, err := d.externals(origin)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, class := range classes {
+		if err := d.RemoveNode(class); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(classes), nil
+}
+
+// Collapse replaces the external classes of origin with their packages, so
+// that depending on any class of a package becomes a dependency on the
+// package itself. The import sites and provenance records add up, and
+// suppressions are dropped.
+// It returns how many classes were replaced.
+func (d *Dependency) Collapse(origin Origin) (int, error) {
+	classes, err := d.externals(origin)
+	if err != nil {
+		return 0, err
+	}
+
+	var collapsed int
+
+	d.depRW.Lock()
+	d.visRW.Lock()
+
+	for _, class := range classes {
+		// Names without a class in them are packages collapsed before,
+		// e.g. in a saved graph, so they are left alone.
+		pkg, ok := importedPackage(class)
+		if !ok || pkg == "" {
+			continue
+		}
+
+		for _, depender := range append([]string(nil), d.visibilities[class]...) {
+			sites := d.sites[edge{depender, class}]
+			records := d.provenance[edge{depender, class}]
+
+			d.mustRemoveDependency(depender, class)
+			d.mustRemoveVisibility(class, depender)
+
+			d.mustAddDependency(depender, pkg)
+			d.mustAddVisibility(pkg, depender)
+			if sites > 1 {
+				d.sites[edge{depender, pkg}] += sites - 1
+			}
+
+			d.provenance[edge{depender, pkg}] = append(d.provenance[edge{depender, pkg}], records...)
+		}
+
+		// External classes aren't parsed, so they rarely depend on
+		// anything, but a saved graph may say otherwise.
+		for _, dependent := range append([]string(nil), d.deps[class]...) {
+			d.mustRemoveDependency(class, dependent)
+			d.mustRemoveVisibility(dependent, class)
+		}
+
+		collapsed++
+	}
+
+	d.visRW.Unlock()
+	d.depRW.Unlock()
+
+	d.resetCycles()
+
+	return collapsed, nil
+}
+
+// importedPackage returns the package of an imported class. As imports of
+// nested classes and static members look like packages too, the package
+// ends before the first segment starting with an upper case letter, going
+// by the Java naming conventions, or before a wildcard. It returns false
+// if there is no such segment, i.e. the name is that of a package.
+func importedPackage(class string) (string, bool) {
+	segments := strings.Split(strings.TrimPrefix(class, "static "), ".")
+
+	for i, segment := range segments {
+		if segment == "*" || segment != "" && segment[0] >= 'A' && segment[0] <= 'Z' {
+			return strings.Join(segments[:i], "."), true
+		}
+	}
+
+	return "", false
+}
+
+// externals returns the classes of origin, which must be an external one.
+func (d *Dependency) externals(origin Origin) ([]string, error) {
+	if origin == Internal {
+		return nil, fmt.Errorf("internal classes are not external")
+	}
+
+	var classes []string
+	for _, class := range d.Classes() {
+		if d.OriginOf(class) == origin {
+			classes = append(classes, class)
+		}
+	}
+
+	return classes, nil
+}
@@ -0,0 +1,165 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+)
+
+func originGraph() *Dependency {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "com.acme.A"})
+	d.SetNode(Node{Name: "com.acme.B"})
+	d.Add("com.acme.A", "com.acme.B")
+	d.Add("com.acme.A", "java.util.List")
+	d.Add("com.acme.A", "java.util.Map")
+	d.Add("com.acme.B", "java.util.List")
+	d.Add("com.acme.B", "org.slf4j.Logger")
+	d.Add("com.acme.B", "org.slf4j.LoggerFactory")
+	d.Add("com.acme.B", "org.slf4j.LoggerFactory")
+
+	return d
+}
+
+func TestIsJDK(t *testing.T) {
+	tests := []struct {
+		class string
+		want  bool
+	}{
+		{"java.util.List", true},
+		{"javax.swing.JList", true},
+		{"javax.xml.parsers.DocumentBuilder", true},
+		{"javax.transaction.xa.XAResource", true},
+		{"com.sun.net.httpserver.HttpServer", true},
+		{"org.w3c.dom.Node", true},
+		{"java", true},
+		{"static java.util.Collections.emptyList", true},
+		{"javafx.scene.Node", false},
+		{"com.sunshine.Foo", false},
+		{"org.slf4j.Logger", false},
+		{"javax.annotation.Nullable", false},
+		{"javax.servlet.http.HttpServlet", false},
+		{"javax.persistence.Entity", false},
+		{"javax.inject.Inject", false},
+		{"javax.ws.rs.GET", false},
+		{"javax.validation.Valid", false},
+		{"javax.transaction.Transactional", false},
+		{"com.sun.jersey.api.client.Client", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			if got := IsJDK(tt.class); got != tt.want {
+				t.Errorf("IsJDK() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependency_OriginOf(t *testing.T) {
+	d := originGraph()
+
+	tests := []struct {
+		class string
+		want  Origin
+	}{
+		{"com.acme.A", Internal},
+		{"java.util.List", JDK},
+		{"org.slf4j.Logger", ThirdParty},
+		{"com.acme.Unknown", ThirdParty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			if got := d.OriginOf(tt.class); got != tt.want {
+				t.Errorf("OriginOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependency_Drop(t *testing.T) {
+	d := originGraph()
+
+	n, err := d.Drop(JDK)
+	if err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+
+	if n != 2 {
+		t.Errorf("Drop() = %d, want 2", n)
+	}
+
+	want := []string{"com.acme.A", "com.acme.B", "org.slf4j.Logger", "org.slf4j.LoggerFactory"}
+	if got := d.Classes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Classes() = %v, want %v", got, want)
+	}
+
+	if _, err := d.Drop(Internal); err == nil {
+		t.Errorf("Drop(Internal) succeeded")
+	}
+}
+
+func TestDependency_Collapse(t *testing.T) {
+	d := originGraph()
+
+	n, err := d.Collapse(ThirdParty)
+	if err != nil {
+		t.Fatalf("Collapse() error = %v", err)
+	}
+
+	if n != 2 {
+		t.Errorf("Collapse() = %d, want 2", n)
+	}
+
+	want := []string{"java.util.List", "org.slf4j"}
+	if got := d.Dependencies("com.acme.B"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependencies(com.acme.B) = %v, want %v", got, want)
+	}
+
+	if got := d.ImportSites("com.acme.B", "org.slf4j"); got != 3 {
+		t.Errorf("ImportSites(com.acme.B, org.slf4j) = %d, want 3", got)
+	}
+
+	if got := d.OriginOf("org.slf4j"); got != ThirdParty {
+		t.Errorf("OriginOf(org.slf4j) = %v, want %v", got, ThirdParty)
+	}
+
+	if _, err := d.Collapse(JDK); err != nil {
+		t.Fatalf("Collapse(JDK) error = %v", err)
+	}
+
+	if got := d.Dependents("java.util"); !reflect.DeepEqual(got, []string{"com.acme.A", "com.acme.B"}) {
+		t.Errorf("Dependents(java.util) = %v, want [com.acme.A com.acme.B]", got)
+	}
+
+	if n, err := d.Collapse(JDK); err != nil || n != 0 {
+		t.Errorf("Collapse(JDK) again = %d, %v, want 0, nil", n, err)
+	}
+
+	if got := d.Dependents("java.util"); !reflect.DeepEqual(got, []string{"com.acme.A", "com.acme.B"}) {
+		t.Errorf("Dependents(java.util) after collapsing again = %v, want [com.acme.A com.acme.B]", got)
+	}
+
+	if _, err := d.Collapse(Internal); err == nil {
+		t.Errorf("Collapse(Internal) succeeded")
+	}
+}
+
+func TestDependency_Collapse_imports(t *testing.T) {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "com.acme.A"})
+	d.Add("com.acme.A", "static org.junit.Assert.assertEquals")
+	d.Add("com.acme.A", "static java.lang.Math.max")
+	d.Add("com.acme.A", "java.util.Map.Entry")
+	d.Add("com.acme.A", "java.util.concurrent.atomic.AtomicLong")
+	d.Add("com.acme.A", "org.junit.jupiter.api.*")
+
+	for _, origin := range []Origin{JDK, ThirdParty} {
+		if _, err := d.Collapse(origin); err != nil {
+			t.Fatalf("Collapse(%v) error = %v", origin, err)
+		}
+	}
+
+	want := []string{"java.lang", "java.util", "java.util.concurrent.atomic", "org.junit", "org.junit.jupiter.api"}
+	if got := d.Dependencies("com.acme.A"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependencies() = %v, want %v", got, want)
+	}
+}
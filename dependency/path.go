@@ -0,0 +1,77 @@
+package dependency
+
+// ShortestPath returns the shortest chain of dependencies leading from
+// one class to the other, including both ends. It returns nil if from
+// doesn't depend on to, not even transitively.
+func (d *Dependency) ShortestPath(from, to string) []string {
+	if from == to {
+		return []string{from}
+	}
+
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+
+	for len(queue) != 0 {
+		class := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range d.Dependencies(class) {
+			if _, ok := prev[dep]; ok {
+				continue
+			}
+
+			prev[dep] = class
+
+			if dep == to {
+				return unwind(prev, from, to)
+			}
+
+			queue = append(queue, dep)
+		}
+	}
+
+	return nil
+}
+
+// unwind follows the prev links back from to, and returns the path in
+// from -> to order.
+func unwind(prev map[string]string, from, to string) []string {
+	var path []string
+	for class := to; class != from; class = prev[class] {
+		path = append(path, class)
+	}
+
+	path = append(path, from)
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// Impact returns every class that depends on class, directly or
+// transitively, mapped to the length of the shortest dependency chain
+// leading to it. These are the classes affected by a change of class.
+func (d *Dependency) Impact(class string) map[string]int {
+	dist := map[string]int{class: 0}
+	queue := []string{class}
+
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range d.Dependents(c) {
+			if _, ok := dist[dep]; ok {
+				continue
+			}
+
+			dist[dep] = dist[c] + 1
+			queue = append(queue, dep)
+		}
+	}
+
+	delete(dist, class)
+
+	return dist
+}
@@ -0,0 +1,59 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependency_ShortestPath(t *testing.T) {
+	d := NewWithCycles(true)
+	for _, e := range [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"a", "x"}, {"x", "d"}, {"d", "a"}} {
+		d.Add(e[0], e[1])
+	}
+	d.Add("e", "f")
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+	}{
+		{"direct", "a", "b", []string{"a", "b"}},
+		{"shortest", "a", "d", []string{"a", "x", "d"}},
+		{"through cycle", "b", "x", []string{"b", "c", "d", "a", "x"}},
+		{"same", "a", "a", []string{"a"}},
+
+		{"unreachable", "a", "f", nil},
+		{"unknown", "nope", "a", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.ShortestPath(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dependency.ShortestPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependency_Impact(t *testing.T) {
+	d := NewWithCycles(true)
+	for _, e := range [][2]string{{"a", "b"}, {"b", "c"}, {"x", "c"}, {"c", "a"}, {"c", "z"}} {
+		d.Add(e[0], e[1])
+	}
+
+	tests := []struct {
+		name  string
+		class string
+		want  map[string]int
+	}{
+		{"leaf", "z", map[string]int{"c": 1, "b": 2, "x": 2, "a": 3}},
+		{"cycle", "a", map[string]int{"c": 1, "b": 2, "x": 2}},
+		{"root", "x", map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Impact(tt.class); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dependency.Impact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
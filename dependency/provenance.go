@@ -0,0 +1,81 @@
+package dependency
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EdgeKindStaticImport is the kind of the provenance records of static
+// imports. Their dependencies are of kind EdgeKindImport all the same.
+const EdgeKindStaticImport = "static-import"
+
+// Provenance tells where a dependency comes from: the statement creating
+// it, and where that is in the sources. Line and Column start at 1. Kind
+// is EdgeKindImport or EdgeKindStaticImport.
+type Provenance struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column,omitempty"`
+	Statement string `json:"statement,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+}
+
+func (p Provenance) String() string {
+	if p.Column == 0 {
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// AddProvenance records where the dependency from depender to dependent,
+// which must have been added already, comes from. A dependency may have
+// several records, e.g. if it is imported twice; a record that is already
+// known is ignored.
+func (d *Dependency) AddProvenance(depender, dependent string, p Provenance) error {
+	if p.File == "" || p.Line < 1 {
+		return fmt.Errorf("provenance needs a file and a line")
+	}
+
+	d.depRW.Lock()
+	defer d.depRW.Unlock()
+
+	e := edge{depender, dependent}
+	if d.sites[e] == 0 {
+		return fmt.Errorf("unknown dependency: %s -> %s", depender, dependent)
+	}
+
+	for _, known := range d.provenance[e] {
+		if known == p {
+			return nil
+		}
+	}
+
+	d.provenance[e] = append(d.provenance[e], p)
+
+	return nil
+}
+
+// Provenance returns where the dependency from depender to dependent comes
+// from, sorted by file and position. It is empty if that isn't known, e.g.
+// for graphs built without it.
+func (d *Dependency) Provenance(depender, dependent string) []Provenance {
+	d.depRW.RLock()
+	records := append([]Provenance(nil), d.provenance[edge{depender, dependent}]...)
+	d.depRW.RUnlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+
+	return records
+}
@@ -0,0 +1,136 @@
+package dependency
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDependency_AddProvenance(t *testing.T) {
+	d := NewWithCycles(true)
+	d.Add("a.A", "b.B")
+	d.Add("a.A", "b.B")
+
+	second := Provenance{File: "A.java", Line: 5, Column: 1, Statement: "import b.B;", Kind: EdgeKindImport}
+	first := Provenance{File: "A.java", Line: 3, Column: 1, Statement: "import b.B;", Kind: EdgeKindImport}
+
+	for _, p := range []Provenance{second, first, second} {
+		if err := d.AddProvenance("a.A", "b.B", p); err != nil {
+			t.Fatalf("AddProvenance() error = %v", err)
+		}
+	}
+
+	if got, want := d.Provenance("a.A", "b.B"), []Provenance{first, second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Provenance() = %v, want %v", got, want)
+	}
+
+	if got := d.Provenance("b.B", "a.A"); got != nil {
+		t.Errorf("Provenance() of a missing dependency = %v, want nil", got)
+	}
+
+	if err := d.AddProvenance("b.B", "a.A", first); err == nil {
+		t.Errorf("AddProvenance() of a missing dependency succeeded")
+	}
+
+	if err := d.AddProvenance("a.A", "b.B", Provenance{File: "A.java"}); err == nil {
+		t.Errorf("AddProvenance() without a line succeeded")
+	}
+}
+
+func TestDependency_Provenance_follows(t *testing.T) {
+	p := Provenance{File: "A.java", Line: 3, Column: 1, Statement: "import b.B;", Kind: EdgeKindImport}
+
+	newGraph := func() *Dependency {
+		d := NewWithCycles(true)
+		d.SetNode(Node{Name: "a.A", File: "A.java"})
+		d.Add("a.A", "b.B")
+		d.AddProvenance("a.A", "b.B", p)
+
+		return d
+	}
+
+	t.Run("Clone", func(t *testing.T) {
+		d := newGraph()
+		c := d.Clone()
+		d.Remove("a.A", "b.B")
+
+		if got := c.Provenance("a.A", "b.B"); !reflect.DeepEqual(got, []Provenance{p}) {
+			t.Errorf("Provenance() of the clone = %v, want %v", got, []Provenance{p})
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		d := newGraph()
+		d.Remove("a.A", "b.B")
+		d.Add("a.A", "b.B")
+
+		if got := d.Provenance("a.A", "b.B"); got != nil {
+			t.Errorf("Provenance() after Remove = %v, want nil", got)
+		}
+	})
+
+	t.Run("MoveClass", func(t *testing.T) {
+		d := newGraph()
+		d.MoveClass("b.B", "c")
+
+		if got := d.Provenance("a.A", "c.B"); !reflect.DeepEqual(got, []Provenance{p}) {
+			t.Errorf("Provenance() after MoveClass = %v, want %v", got, []Provenance{p})
+		}
+	})
+
+	t.Run("Collapse", func(t *testing.T) {
+		d := newGraph()
+		d.Collapse(ThirdParty)
+
+		if got := d.Provenance("a.A", "b"); !reflect.DeepEqual(got, []Provenance{p}) {
+			t.Errorf("Provenance() after Collapse = %v, want %v", got, []Provenance{p})
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := newGraph().WriteJSON(&buf); err != nil {
+			t.Fatalf("WriteJSON() error = %v", err)
+		}
+
+		d, err := ReadJSON(&buf)
+		if err != nil {
+			t.Fatalf("ReadJSON() error = %v", err)
+		}
+
+		if got := d.Provenance("a.A", "b.B"); !reflect.DeepEqual(got, []Provenance{p}) {
+			t.Errorf("Provenance() after a JSON round trip = %v, want %v", got, []Provenance{p})
+		}
+	})
+
+	t.Run("JSON Lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := newGraph().WriteJSONLines(&buf); err != nil {
+			t.Fatalf("WriteJSONLines() error = %v", err)
+		}
+
+		d, err := ReadJSONLines(&buf)
+		if err != nil {
+			t.Fatalf("ReadJSONLines() error = %v", err)
+		}
+
+		if got := d.Provenance("a.A", "b.B"); !reflect.DeepEqual(got, []Provenance{p}) {
+			t.Errorf("Provenance() after a JSON Lines round trip = %v, want %v", got, []Provenance{p})
+		}
+	})
+}
+
+func TestProvenance_String(t *testing.T) {
+	tests := []struct {
+		p    Provenance
+		want string
+	}{
+		{Provenance{File: "A.java", Line: 3, Column: 5}, "A.java:3:5"},
+		{Provenance{File: "A.java", Line: 3}, "A.java:3"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package dependency
+
+import "sort"
+
+// StronglyConnectedComponents returns the groups of classes that all
+// (transitively) depend on each other, i.e. the classes that take part in
+// at least one dependency cycle. Each component is sorted, and components
+// are ordered by their first class.
+func (d *Dependency) StronglyConnectedComponents() [][]string {
+	g := d.cycleSnapshot()
+
+	var sccs [][]string
+	for _, comp := range g.sccs() {
+		names := make([]string, len(comp))
+		for i, v := range comp {
+			names[i] = g.names[v]
+		}
+
+		sort.Strings(names)
+		sccs = append(sccs, names)
+	}
+
+	sort.Slice(sccs, func(i, j int) bool {
+		return sccs[i][0] < sccs[j][0]
+	})
+
+	return sccs
+}
+
+// sccs runs Tarjan's algorithm on the graph and returns the components
+// that contain a cycle: either more than one class, or a single class
+// depending on itself.
+func (g *graph) sccs() [][]int {
+	var (
+		index   = 0
+		indices = make([]int, len(g.names))
+		lowlink = make([]int, len(g.names))
+		onStack = make([]bool, len(g.names))
+		stack   []int
+		comps   [][]int
+	)
+
+	for i := range indices {
+		indices[i] = -1
+	}
+
+	var connect func(v int)
+	connect = func(v int) {
+		indices[v] = index
+		lowlink[v] = index
+		index++
+
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.out[v] {
+			if indices[w] == -1 {
+				connect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && indices[w] < lowlink[v] {
+				lowlink[v] = indices[w]
+			}
+		}
+
+		if lowlink[v] != indices[v] {
+			return
+		}
+
+		var comp []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+
+			comp = append(comp, w)
+
+			if w == v {
+				break
+			}
+		}
+
+		if len(comp) > 1 || g.hasEdge(v, v) {
+			comps = append(comps, comp)
+		}
+	}
+
+	for v := range g.names {
+		if indices[v] == -1 {
+			connect(v)
+		}
+	}
+
+	return comps
+}
+
+func (g *graph) hasEdge(from, to int) bool {
+	for _, w := range g.out[from] {
+		if w == to {
+			return true
+		}
+	}
+
+	return false
+}
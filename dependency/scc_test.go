@@ -0,0 +1,32 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependency_StronglyConnectedComponents(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges [][2]string
+		want  [][]string
+	}{
+		{"acyclic", [][2]string{{"a", "b"}, {"b", "c"}}, nil},
+		{"direct cycle", [][2]string{{"a", "b"}, {"b", "a"}}, [][]string{{"a", "b"}}},
+		{"self cycle", [][2]string{{"a", "a"}, {"a", "b"}}, [][]string{{"a"}}},
+		{"two components", [][2]string{{"a", "b"}, {"b", "a"}, {"b", "c"}, {"c", "d"}, {"d", "e"}, {"e", "c"}},
+			[][]string{{"a", "b"}, {"c", "d", "e"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewWithCycles(true)
+			for _, e := range tt.edges {
+				d.Add(e[0], e[1])
+			}
+
+			if got := d.StronglyConnectedComponents(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dependency.StronglyConnectedComponents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package dependency
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The methods in this file change the graph the way a refactoring would,
+// so that its effect on cycles and metrics can be seen before touching
+// the sources. They are meant to be used on a Clone of a scanned graph;
+// deleting a dependency is done with Remove.
+
+// MoveClass moves class into pkg, keeping its simple name, and returns
+// its new name. Moving a class into the default package is done by
+// passing an empty pkg.
+func (d *Dependency) MoveClass(class, pkg string) (string, error) {
+	if !d.known(class) {
+		return "", fmt.Errorf("unknown class: %s", class)
+	}
+
+	moved := qualify(pkg, simpleName(class))
+	if moved == class {
+		return class, nil
+	}
+
+	if err := d.rename(map[string]string{class: moved}); err != nil {
+		return "", err
+	}
+
+	return moved, nil
+}
+
+// MergePackages moves every class of the package from into the package
+// into, which may or may not exist already. Subpackages of from are left
+// where they are.
+func (d *Dependency) MergePackages(from, into string) error {
+	if from == into {
+		return fmt.Errorf("cannot merge package %s into itself", from)
+	}
+
+	classes := d.packageClasses(from)
+	if len(classes) == 0 {
+		return fmt.Errorf("unknown package: %s", from)
+	}
+
+	return d.SplitPackage(from, into, classes)
+}
+
+// SplitPackage moves the given classes of pkg into newPkg, leaving the
+// rest of pkg in place.
+func (d *Dependency) SplitPackage(pkg, newPkg string, classes []string) error {
+	if pkg == newPkg {
+		return fmt.Errorf("cannot split package %s into itself", pkg)
+	}
+
+	if len(classes) == 0 {
+		return fmt.Errorf("no classes to move out of %s", pkg)
+	}
+
+	mapping := make(map[string]string, len(classes))
+	for _, class := range classes {
+		if PackageOf(class) != pkg {
+			return fmt.Errorf("%s is not in package %s", class, pkg)
+		}
+
+		if !d.known(class) {
+			return fmt.Errorf("unknown class: %s", class)
+		}
+
+		mapping[class] = qualify(newPkg, simpleName(class))
+	}
+
+	return d.rename(mapping)
+}
+
+// ExtractInterface introduces the abstract class iface, implemented by
+// class, and makes users depend on it instead of class. If users is
+// empty, every class depending on class is switched over.
+func (d *Dependency) ExtractInterface(class, iface string, users []string) error {
+	if !d.known(class) {
+		return fmt.Errorf("unknown class: %s", class)
+	}
+
+	if iface == "" {
+		return fmt.Errorf("empty interface name")
+	}
+
+	if d.known(iface) {
+		return fmt.Errorf("class already exists: %s", iface)
+	}
+
+	if len(users) == 0 {
+		users = d.Dependents(class)
+	}
+
+	for _, user := range users {
+		if _, ok := removeString(d.Dependencies(user), class); !ok {
+			return fmt.Errorf("%s does not depend on %s", user, class)
+		}
+	}
+
+	n, _ := d.Node(class)
+	err := d.SetNode(Node{
+		Name:      iface,
+		File:      n.File,
+		Module:    n.Module,
+		SourceSet: n.SourceSet,
+		Abstract:  true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The interface depends on nothing, so none of the changes below can
+	// introduce a cycle.
+	for _, user := range users {
+		if err := d.Remove(user, class); err != nil {
+			return err
+		}
+
+		if err := d.Add(user, iface); err != nil {
+			return err
+		}
+	}
+
+	return d.Add(class, iface)
+}
+
+// known tells whether class is declared or takes part in a dependency.
+func (d *Dependency) known(class string) bool {
+	if _, ok := d.Node(class); ok {
+		return true
+	}
+
+	return len(d.Dependencies(class)) > 0 || len(d.Dependents(class)) > 0
+}
+
+// packageClasses returns the classes directly in pkg.
+func (d *Dependency) packageClasses(pkg string) []string {
+	var classes []string
+	for _, class := range d.Classes() {
+		if PackageOf(class) == pkg {
+			classes = append(classes, class)
+		}
+	}
+
+	return classes
+}
+
+// rename gives the classes in mapping their new names everywhere in the
+// graph. The renaming may not make two classes share a name, so no cycle
+// can appear or disappear because of it.
+func (d *Dependency) rename(mapping map[string]string) error {
+	taken := make(map[string]string)
+	for _, class := range d.Classes() {
+		if _, ok := mapping[class]; !ok {
+			taken[class] = class
+		}
+	}
+
+	olds := make([]string, 0, len(mapping))
+	for old := range mapping {
+		olds = append(olds, old)
+	}
+
+	sort.Strings(olds)
+
+	for _, old := range olds {
+		name := mapping[old]
+		if name == "" {
+			return fmt.Errorf("empty new name for %s", old)
+		}
+
+		if other, ok := taken[name]; ok {
+			return fmt.Errorf("cannot rename %s to %s: taken by %s", old, name, other)
+		}
+
+		taken[name] = old
+	}
+
+	to := func(class string) string {
+		if name, ok := mapping[class]; ok {
+			return name
+		}
+
+		return class
+	}
+
+	d.depRW.Lock()
+	d.deps = renameLists(d.deps, to)
+
+	sites := make(map[edge]int, len(d.sites))
+	for e, n := range d.sites {
+		sites[edge{to(e.from), to(e.to)}] = n
+	}
+	d.sites = sites
+
+	suppressed := make(map[edge]string, len(d.suppressed))
+	for e, reason := range d.suppressed {
+		suppressed[edge{to(e.from), to(e.to)}] = reason
+	}
+	d.suppressed = suppressed
+
+	provenance := make(map[edge][]Provenance, len(d.provenance))
+	for e, records := range d.provenance {
+		provenance[edge{to(e.from), to(e.to)}] = records
+	}
+	d.provenance = provenance
+	d.depRW.Unlock()
+
+	d.visRW.Lock()
+	d.visibilities = renameLists(d.visibilities, to)
+	d.visRW.Unlock()
+
+	d.nodeRW.Lock()
+	nodes := make(map[string]Node, len(d.nodes))
+	for name, n := range d.nodes {
+		n.Name = to(name)
+		nodes[n.Name] = n
+	}
+	d.nodes = nodes
+	d.nodeRW.Unlock()
+
+	d.resetCycles()
+
+	return nil
+}
+
+func renameLists(lists map[string][]string, to func(string) string) map[string][]string {
+	res := make(map[string][]string, len(lists))
+	for key, list := range lists {
+		renamed := make([]string, len(list))
+		for i, class := range list {
+			renamed[i] = to(class)
+		}
+
+		res[to(key)] = renamed
+	}
+
+	return res
+}
+
+func simpleName(class string) string {
+	return class[strings.LastIndex(class, ".")+1:]
+}
+
+func qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+
+	return pkg + "." + name
+}
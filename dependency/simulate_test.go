@@ -0,0 +1,202 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+)
+
+func simulationGraph() *Dependency {
+	d := NewWithCycles(true)
+	d.SetNode(Node{Name: "a.A", File: "a/A.java"})
+	d.SetNode(Node{Name: "b.B", File: "b/B.java"})
+	d.Add("a.A", "b.B")
+	d.Add("b.B", "a.A")
+	d.Add("a.A2", "b.B")
+	d.Add("c.C", "b.B")
+
+	return d
+}
+
+func TestDependency_MoveClass(t *testing.T) {
+	d := simulationGraph()
+
+	moved, err := d.MoveClass("a.A", "b")
+	if err != nil {
+		t.Fatalf("MoveClass() error = %v", err)
+	}
+
+	if moved != "b.A" {
+		t.Errorf("MoveClass() = %q, want %q", moved, "b.A")
+	}
+
+	if got := d.Dependencies("b.A"); !reflect.DeepEqual(got, []string{"b.B"}) {
+		t.Errorf("Dependencies(b.A) = %v, want [b.B]", got)
+	}
+
+	if got := d.Dependents("b.A"); !reflect.DeepEqual(got, []string{"b.B"}) {
+		t.Errorf("Dependents(b.A) = %v, want [b.B]", got)
+	}
+
+	if n, ok := d.Node("b.A"); !ok || n.Name != "b.A" || n.File != "a/A.java" {
+		t.Errorf("Node(b.A) = %v, %v, want the node of a.A renamed", n, ok)
+	}
+
+	if _, ok := d.Node("a.A"); ok {
+		t.Errorf("Node(a.A) still exists")
+	}
+
+	if got := d.ImportSites("b.A", "b.B"); got != 1 {
+		t.Errorf("ImportSites(b.A, b.B) = %d, want 1", got)
+	}
+
+	if _, err := d.MoveClass("nope.N", "b"); err == nil {
+		t.Errorf("MoveClass() of an unknown class succeeded")
+	}
+
+	if _, err := d.MoveClass("b.A", "a"); err != nil {
+		t.Errorf("MoveClass() back error = %v", err)
+	}
+
+	if _, err := d.MoveClass("a.A", ""); err != nil {
+		t.Errorf("MoveClass() into the default package error = %v", err)
+	}
+
+	if got := d.Dependencies("A"); !reflect.DeepEqual(got, []string{"b.B"}) {
+		t.Errorf("Dependencies(A) = %v, want [b.B]", got)
+	}
+}
+
+func TestDependency_MoveClass_collision(t *testing.T) {
+	d := simulationGraph()
+	d.Add("b.A", "c.C")
+
+	if _, err := d.MoveClass("a.A", "b"); err == nil {
+		t.Fatalf("MoveClass() onto an existing class succeeded")
+	}
+
+	if got := d.Dependencies("a.A"); !reflect.DeepEqual(got, []string{"b.B"}) {
+		t.Errorf("failed MoveClass() changed the graph: Dependencies(a.A) = %v", got)
+	}
+}
+
+func TestDependency_MergePackages(t *testing.T) {
+	d := simulationGraph()
+
+	if err := d.MergePackages("a", "b"); err != nil {
+		t.Fatalf("MergePackages() error = %v", err)
+	}
+
+	want := []string{"b.A", "b.A2", "b.B", "c.C"}
+	if got := d.Classes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Classes() = %v, want %v", got, want)
+	}
+
+	// Merging the packages keeps the cycle between their classes.
+	if _, ok := d.CheckCyclicDependencies(); ok {
+		t.Errorf("CheckCyclicDependencies() found no cycle")
+	}
+
+	for _, m := range d.PackageMetrics() {
+		if m.Package == "a" {
+			t.Errorf("package a still has metrics after the merge")
+		}
+	}
+
+	if err := d.MergePackages("nope", "b"); err == nil {
+		t.Errorf("MergePackages() of an unknown package succeeded")
+	}
+
+	if err := d.MergePackages("b", "b"); err == nil {
+		t.Errorf("MergePackages() into itself succeeded")
+	}
+}
+
+func TestDependency_SplitPackage(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkg     string
+		classes []string
+		want    []string
+		wantErr bool
+	}{
+		{"Split", "a", []string{"a.A2"}, []string{"a.A", "b.B", "c.C", "n.A2"}, false},
+		{"Other package", "a", []string{"b.B"}, nil, true},
+		{"Unknown class", "a", []string{"a.Nope"}, nil, true},
+		{"No classes", "a", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := simulationGraph()
+
+			err := d.SplitPackage(tt.pkg, "n", tt.classes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitPackage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := d.Classes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Classes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependency_ExtractInterface(t *testing.T) {
+	tests := []struct {
+		name      string
+		users     []string
+		wantUsers []string
+		wantLeft  []string
+		wantErr   bool
+	}{
+		{"All users", nil, []string{"a.A", "a.A2", "b.B", "c.C"}, nil, false},
+		{"Some users", []string{"a.A"}, []string{"a.A", "b.B"}, []string{"a.A2", "c.C"}, false},
+		{"Not a user", []string{"b.B"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := simulationGraph()
+
+			err := d.ExtractInterface("b.B", "api.IB", tt.users)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractInterface() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := d.Dependents("api.IB"); !reflect.DeepEqual(got, tt.wantUsers) {
+				t.Errorf("Dependents(api.IB) = %v, want %v", got, tt.wantUsers)
+			}
+
+			if got := d.Dependents("b.B"); !reflect.DeepEqual(got, tt.wantLeft) {
+				t.Errorf("Dependents(b.B) = %v, want %v", got, tt.wantLeft)
+			}
+
+			if n, ok := d.Node("api.IB"); !ok || !n.Abstract {
+				t.Errorf("Node(api.IB) = %v, %v, want an abstract node", n, ok)
+			}
+		})
+	}
+
+	d := simulationGraph()
+	if err := d.ExtractInterface("b.B", "a.A", nil); err == nil {
+		t.Errorf("ExtractInterface() onto an existing class succeeded")
+	}
+}
+
+func TestDependency_ExtractInterface_breaksCycle(t *testing.T) {
+	d := simulationGraph()
+
+	if err := d.ExtractInterface("a.A", "b.IA", nil); err != nil {
+		t.Fatalf("ExtractInterface() error = %v", err)
+	}
+
+	if cycles, ok := d.CheckCyclicDependencies(); !ok {
+		t.Errorf("CheckCyclicDependencies() = %v, want no cycles", cycles)
+	}
+}
@@ -0,0 +1,89 @@
+package dependency
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Suppression is a dependency that was marked as accepted at the source,
+// along with the reason given for it.
+type Suppression struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// Suppress marks the dependency from depender to dependent as accepted.
+// Suppressed dependencies remain part of the graph, but are left out when
+// looking for dependency cycles.
+func (d *Dependency) Suppress(depender, dependent, reason string) error {
+	if depender == "" || dependent == "" {
+		return fmt.Errorf("empty dependant or dependee")
+	}
+
+	d.depRW.Lock()
+	d.suppressed[edge{depender, dependent}] = reason
+	d.depRW.Unlock()
+
+	return nil
+}
+
+// Suppressed returns why the dependency from depender to dependent is
+// accepted, and whether it is.
+func (d *Dependency) Suppressed(depender, dependent string) (string, bool) {
+	d.depRW.RLock()
+	reason, ok := d.suppressed[edge{depender, dependent}]
+	d.depRW.RUnlock()
+
+	return reason, ok
+}
+
+// Suppressions returns every suppressed dependency, ordered by depender,
+// then by dependent.
+func (d *Dependency) Suppressions() []Suppression {
+	d.depRW.RLock()
+	list := make([]Suppression, 0, len(d.suppressed))
+	for e, reason := range d.suppressed {
+		list = append(list, Suppression{From: e.from, To: e.to, Reason: reason})
+	}
+	d.depRW.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].From != list[j].From {
+			return list[i].From < list[j].From
+		}
+
+		return list[i].To < list[j].To
+	})
+
+	return list
+}
+
+// SuppressedCycleEdges returns the suppressed dependencies that would
+// close a dependency cycle if they weren't suppressed.
+func (d *Dependency) SuppressedCycleEdges() []Suppression {
+	var list []Suppression
+	for _, s := range d.Suppressions() {
+		if d.ShortestPath(s.To, s.From) != nil {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
+
+// activeDependencies returns the dependencies of depender that are not
+// suppressed.
+func (d *Dependency) activeDependencies(depender string) []string {
+	d.depRW.RLock()
+	defer d.depRW.RUnlock()
+
+	var deps []string
+	for _, dep := range d.deps[depender] {
+		if _, ok := d.suppressed[edge{depender, dep}]; !ok {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
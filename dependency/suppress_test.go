@@ -0,0 +1,54 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependency_Suppress(t *testing.T) {
+	d := New()
+
+	if err := d.Suppress("", "a", "reason"); err == nil {
+		t.Errorf("Dependency.Suppress() expected error for empty depender")
+	}
+
+	d.Add("a", "b")
+	d.Add("b", "c")
+	d.Suppress("b", "a", "framework")
+
+	// Would be a cycle, but it was suppressed.
+	if err := d.Add("b", "a"); err != nil {
+		t.Errorf("Dependency.Add() error = %v for suppressed cycle", err)
+	}
+
+	if cycles, ok := d.CheckCyclicDependencies(); !ok {
+		t.Errorf("Dependency.CheckCyclicDependencies() = %v for suppressed cycle", cycles)
+	}
+
+	if sccs := d.StronglyConnectedComponents(); len(sccs) != 0 {
+		t.Errorf("Dependency.StronglyConnectedComponents() = %v for suppressed cycle", sccs)
+	}
+
+	if reason, ok := d.Suppressed("b", "a"); !ok || reason != "framework" {
+		t.Errorf("Dependency.Suppressed() = %q, %v, want framework, true", reason, ok)
+	}
+
+	if _, ok := d.Suppressed("a", "b"); ok {
+		t.Errorf("Dependency.Suppressed() reported an unsuppressed dependency")
+	}
+
+	d.Suppress("c", "x", "not a cycle")
+
+	if got, want := d.Suppressions(), []Suppression{{"b", "a", "framework"}, {"c", "x", "not a cycle"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependency.Suppressions() = %v, want %v", got, want)
+	}
+
+	if got, want := d.SuppressedCycleEdges(), []Suppression{{"b", "a", "framework"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependency.SuppressedCycleEdges() = %v, want %v", got, want)
+	}
+
+	// Suppressed dependencies still count for everything else.
+	if got := d.Dependencies("b"); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("Dependency.Dependencies() = %v, want [a c]", got)
+	}
+}
@@ -13,11 +13,45 @@ import (
 var (
 	dep  *dependency.Dependency
 	errs []error
+	opts Options
 )
 
+// Options tweak how BuildDependenciesWithOptions scans the sources.
+type Options struct {
+	AllowCycles bool
+
+	// Exclude skips the files and directories whose name or path matches
+	// any of these glob patterns.
+	Exclude []string
+
+	// Cache, if set, is used to skip parsing the files that didn't change
+	// since they were last scanned.
+	Cache *Cache
+
+	// JDK and ThirdParty tell what to do with the JDK and the third-party
+	// classes the sources import, see dependency.Origin. Both are kept by
+	// default.
+	JDK        External
+	ThirdParty External
+}
+
 // BuildDependencies walks through all of the paths to build up a dependency tree
 func BuildDependencies(allowCycles bool, roots []string) (*dependency.Dependency, error) {
-	dep = dependency.NewWithCycles(allowCycles)
+	return BuildDependenciesWithOptions(roots, Options{AllowCycles: allowCycles})
+}
+
+// BuildDependenciesWithOptions walks through all of the paths to build up a
+// dependency tree, as set up by options.
+func BuildDependenciesWithOptions(roots []string, options Options) (*dependency.Dependency, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	dep = dependency.NewWithCycles(options.AllowCycles)
+	errs = nil
+	opts = options
+
+	resetModules()
 
 	var wg sync.WaitGroup
 
@@ -39,6 +73,10 @@ func BuildDependencies(allowCycles bool, roots []string) (*dependency.Dependency
 		return nil, fmt.Errorf(errMsg)
 	}
 
+	if err := ReduceExternal(dep, options); err != nil {
+		return nil, err
+	}
+
 	return dep, nil
 }
 
@@ -58,6 +96,14 @@ func walker(path string, info os.FileInfo, err error) error {
 		return fmt.Errorf("%v: can't visit %q: %v", op, path, err)
 	}
 
+	if excluded(opts.Exclude, path, info) {
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	}
+
 	if info.IsDir() {
 		//log.Printf("visited folder: %q", path)
 		return nil
@@ -68,22 +114,72 @@ func walker(path string, info os.FileInfo, err error) error {
 		return nil
 	}
 
-	fqcn, err := extractFQCN(path)
+	src, err := readSource(opts.Cache, path, info)
 	if err != nil {
-		return fmt.Errorf("FQCN extract: %v", err)
+		return fmt.Errorf("%v: %v", op, err)
 	}
 
-	imports, err := extractImports(path)
+	err = dep.SetNode(dependency.Node{
+		Name:      src.FQCN,
+		File:      path,
+		Module:    moduleOf(path),
+		SourceSet: sourceSetOf(path),
+		Abstract:  src.Abstract,
+	})
 	if err != nil {
-		return fmt.Errorf("%v: extract imports failed: %v", op, err)
+		return fmt.Errorf("failed adding class: %v", err)
 	}
 
-	for _, imp := range imports {
-		err := dep.Add(fqcn, imp)
+	for _, imp := range src.Imports {
+		if reason, ok := src.Suppressions.reasonFor(imp); ok {
+			if err := dep.Suppress(src.FQCN, imp, reason); err != nil {
+				return fmt.Errorf("failed suppressing dependency: %v", err)
+			}
+		}
+
+		err := dep.Add(src.FQCN, imp)
 		if err != nil {
 			return fmt.Errorf("failed adding dependency: %v", err)
 		}
 	}
 
+	return addProvenance(dep, path, src)
+}
+
+// addProvenance records where the imports of src are in the file at path.
+func addProvenance(d *dependency.Dependency, path string, src source) error {
+	for _, site := range src.Sites {
+		if err := d.AddProvenance(src.FQCN, site.Import, site.provenance(path)); err != nil {
+			return fmt.Errorf("failed recording provenance: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// validateExclude checks that the exclude patterns are valid globs.
+func validateExclude(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %v", pattern, err)
+		}
+	}
+
 	return nil
 }
+
+// excluded checks whether the file or directory matches any of the
+// exclude patterns, either by name or by path.
+func excluded(patterns []string, path string, info os.FileInfo) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, info.Name()); ok {
+			return true
+		}
+
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+
+	return false
+}
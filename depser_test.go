@@ -0,0 +1,87 @@
+package depser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+func writeSources(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed writing %s: %v", name, err)
+		}
+	}
+
+	return root
+}
+
+func TestBuildDependenciesWithOptions(t *testing.T) {
+	root := writeSources(t, map[string]string{
+		"src/main/java/com/a/A.java":         "package com.a;\n\nimport com.b.B;\n\npublic class A {}\n",
+		"src/main/java/com/b/B.java":         "package com.b;\n\nimport java.util.List;\n\npublic abstract class B {}\n",
+		"src/test/java/com/a/ATest.java":     "package com.a;\n\nimport org.junit.Test;\n\npublic class ATest {}\n",
+		"src/main/java/com/a/Generated.java": "package com.a;\n\nimport com.c.C;\n\npublic class Generated {}\n",
+	})
+	defer os.RemoveAll(root)
+
+	tests := []struct {
+		name    string
+		exclude []string
+		want    []string
+		wantErr bool
+	}{
+		{"everything", nil, []string{"com.a.A", "com.a.ATest", "com.a.Generated", "com.b.B", "com.c.C", "java.util.List", "org.junit.Test"}, false},
+		{"exclude dir and file", []string{"test", "Generated.java"}, []string{"com.a.A", "com.b.B", "java.util.List"}, false},
+
+		{"bad pattern", []string{"["}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := BuildDependenciesWithOptions([]string{root}, Options{AllowCycles: true, Exclude: tt.exclude})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildDependenciesWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := d.Classes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("classes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	d, err := BuildDependenciesWithOptions([]string{root}, Options{})
+	if err != nil {
+		t.Fatalf("BuildDependenciesWithOptions() error = %v", err)
+	}
+
+	if n, ok := d.Node("com.b.B"); !ok || !n.Abstract || n.SourceSet != "main" {
+		t.Errorf("node = %+v, %v, want an abstract class in the main source set", n, ok)
+	}
+	want := []dependency.Provenance{{
+		File:      filepath.Join(root, "src/main/java/com/a/A.java"),
+		Line:      3,
+		Column:    1,
+		Statement: "import com.b.B;",
+		Kind:      dependency.EdgeKindImport,
+	}}
+	if got := d.Provenance("com.a.A", "com.b.B"); !reflect.DeepEqual(got, want) {
+		t.Errorf("provenance = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package depser
+
+import (
+	"fmt"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+// External tells what to do with the classes that the sources import, but
+// don't declare. An empty External keeps them.
+type External string
+
+// What can be done with external classes.
+const (
+	// ExternalKeep keeps the classes as they are.
+	ExternalKeep External = "keep"
+
+	// ExternalDrop removes the classes, along with the dependencies on
+	// them.
+	ExternalDrop External = "drop"
+
+	// ExternalCollapse replaces the classes with their packages.
+	ExternalCollapse External = "collapse"
+)
+
+// validateExternal checks that e is a known External.
+func validateExternal(e External) error {
+	switch e {
+	case "", ExternalKeep, ExternalDrop, ExternalCollapse:
+		return nil
+	}
+
+	return fmt.Errorf("unknown handling of external classes %q, expected %s, %s or %s",
+		e, ExternalKeep, ExternalDrop, ExternalCollapse)
+}
+
+// ReduceExternal drops or collapses the external classes of d, as set up
+// by options. Scanning already does this; it is meant for graphs loaded
+// from a file.
+func ReduceExternal(d *dependency.Dependency, options Options) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+
+	for origin, e := range map[dependency.Origin]External{
+		dependency.JDK:        options.JDK,
+		dependency.ThirdParty: options.ThirdParty,
+	} {
+		var err error
+
+		switch e {
+		case ExternalDrop:
+			_, err = d.Drop(origin)
+		case ExternalCollapse:
+			_, err = d.Collapse(origin)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reducesExternal returns true if options drop or collapse any of the
+// external classes.
+func (o Options) reducesExternal() bool {
+	for _, e := range []External{o.JDK, o.ThirdParty} {
+		if e == ExternalDrop || e == ExternalCollapse {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate checks the options that can be invalid.
+func (o Options) validate() error {
+	if err := validateExclude(o.Exclude); err != nil {
+		return err
+	}
+
+	if err := validateExternal(o.JDK); err != nil {
+		return fmt.Errorf("jdk: %v", err)
+	}
+
+	if err := validateExternal(o.ThirdParty); err != nil {
+		return fmt.Errorf("third-party: %v", err)
+	}
+
+	return nil
+}
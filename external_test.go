@@ -0,0 +1,76 @@
+package depser
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBuildDependenciesWithOptions_external(t *testing.T) {
+	root := writeSources(t, map[string]string{
+		"com/a/A.java": "package com.a;\n\nimport com.b.B;\nimport java.util.List;\nimport java.util.Map;\nimport org.slf4j.Logger;\n\npublic class A {}\n",
+		"com/b/B.java": "package com.b;\n\nimport org.slf4j.Logger;\nimport org.slf4j.LoggerFactory;\n\npublic class B {}\n",
+	})
+	defer os.RemoveAll(root)
+
+	tests := []struct {
+		name       string
+		jdk        External
+		thirdParty External
+		want       []string
+		wantErr    bool
+	}{
+		{"Keep", "", ExternalKeep, []string{"com.a.A", "com.b.B", "java.util.List", "java.util.Map", "org.slf4j.Logger", "org.slf4j.LoggerFactory"}, false},
+		{"Drop JDK", ExternalDrop, "", []string{"com.a.A", "com.b.B", "org.slf4j.Logger", "org.slf4j.LoggerFactory"}, false},
+		{"Collapse third-party", "", ExternalCollapse, []string{"com.a.A", "com.b.B", "java.util.List", "java.util.Map", "org.slf4j"}, false},
+		{"Drop JDK, collapse third-party", ExternalDrop, ExternalCollapse, []string{"com.a.A", "com.b.B", "org.slf4j"}, false},
+		{"Unknown", "hide", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep, err := BuildDependenciesWithOptions([]string{root}, Options{
+				AllowCycles: true,
+				JDK:         tt.jdk,
+				ThirdParty:  tt.thirdParty,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildDependenciesWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := dep.Classes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Classes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTree_Graph_external(t *testing.T) {
+	root := writeSources(t, map[string]string{
+		"com/a/A.java": "package com.a;\n\nimport com.b.B;\nimport java.util.List;\n\npublic class A {}\n",
+		"com/b/B.java": "package com.b;\n\nimport org.slf4j.Logger;\n\npublic class B {}\n",
+	})
+	defer os.RemoveAll(root)
+
+	tree, err := NewTree([]string{root}, Options{AllowCycles: true, JDK: ExternalDrop, ThirdParty: ExternalCollapse})
+	if err != nil {
+		t.Fatalf("NewTree() error = %v", err)
+	}
+
+	want := []string{"com.a.A", "com.b.B", "org.slf4j"}
+	if got := tree.Graph().Classes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Graph().Classes() = %v, want %v", got, want)
+	}
+
+	// Reducing a copy keeps the tree able to follow changes.
+	if got := tree.Graph().Classes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("second Graph().Classes() = %v, want %v", got, want)
+	}
+
+	if _, err := NewTree([]string{root}, Options{ThirdParty: "hide"}); err == nil {
+		t.Errorf("NewTree() with an unknown handling of external classes succeeded")
+	}
+}
@@ -2,11 +2,14 @@ package depser
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/djavorszky/depser/dependency"
 )
 
 func extractImports(path string) ([]string, error) {
@@ -31,7 +34,12 @@ func extractImportFrom(r io.Reader) ([]string, error) {
 		line := scanner.Text()
 
 		if strings.HasPrefix(line, "import ") {
-			imports = append(imports, mustParseImport(line))
+			imp, err := parseImport(line)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", op, err)
+			}
+
+			imports = append(imports, imp)
 		}
 
 		if strings.HasPrefix(line, "public") || strings.HasPrefix(line, "class") {
@@ -67,7 +75,11 @@ func extractPackageFrom(r io.Reader) (string, error) {
 		line := scanner.Text()
 
 		if strings.HasPrefix(line, "package") {
-			pkg = mustParsePackage(line)
+			var err error
+			if pkg, err = parsePackage(line); err != nil {
+				return "", fmt.Errorf("%v: %v", op, err)
+			}
+
 			break
 		}
 	}
@@ -79,6 +91,86 @@ func extractPackageFrom(r io.Reader) (string, error) {
 	return pkg, nil
 }
 
+func extractAbstractFrom(r io.Reader) (bool, error) {
+	const op = "extractAbstractFrom(io.Reader)"
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		abstract, err := parseAbstract(scanner.Text())
+		if err == nil {
+			return abstract, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("%v: reading from io.Reader: %v", op, err)
+	}
+
+	return false, nil
+}
+
+// suppressions holds the dependencies a source file marks as accepted.
+// If All is set, the class is annotated with @SuppressDependency and every
+// import is accepted for the same reason. Otherwise Imports holds the
+// reasons of the imports marked with a depser:ignore-cycle comment.
+type suppressions struct {
+	All     bool              `json:"all,omitempty"`
+	Reason  string            `json:"reason,omitempty"`
+	Imports map[string]string `json:"imports,omitempty"`
+}
+
+// reasonFor returns why the import is accepted, and whether it is.
+func (s suppressions) reasonFor(imp string) (string, bool) {
+	if s.All {
+		return s.Reason, true
+	}
+
+	reason, ok := s.Imports[imp]
+
+	return reason, ok
+}
+
+func extractSuppressionsFrom(r io.Reader) (suppressions, error) {
+	const op = "extractSuppressionsFrom(io.Reader)"
+
+	s := suppressions{Imports: make(map[string]string)}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "import ") {
+			if reason, ok := parseIgnoreCycle(line); ok {
+				imp, err := parseImport(line)
+				if err != nil {
+					return suppressions{}, fmt.Errorf("%v: %v", op, err)
+				}
+
+				s.Imports[imp] = reason
+			}
+
+			continue
+		}
+
+		// The annotation may be on the same line as the declaration.
+		if reason, rest, err := parseSuppressDependency(line); err == nil {
+			s.All = true
+			s.Reason = reason
+			line = rest
+		}
+
+		if _, err := parseAbstract(line); err == nil {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return suppressions{}, fmt.Errorf("%v: reading from io.Reader: %v", op, err)
+	}
+
+	return s, nil
+}
+
 func extractFQCN(path string) (string, error) {
 	const op = "extractFQCN"
 
@@ -87,8 +179,120 @@ func extractFQCN(path string) (string, error) {
 		return "", fmt.Errorf("%v: failed extract from %s: %v", op, path, err)
 	}
 
+	return fqcnOf(path, pkg), nil
+}
+
+func fqcnOf(path, pkg string) string {
 	_, fileName := filepath.Split(path)
 	class := strings.Split(fileName, ".")[0]
 
-	return fmt.Sprintf("%s.%s", pkg, class), nil
+	return fmt.Sprintf("%s.%s", pkg, class)
+}
+
+// source holds everything the walker needs from a source file.
+type source struct {
+	FQCN         string       `json:"fqcn"`
+	Abstract     bool         `json:"abstract,omitempty"`
+	Imports      []string     `json:"imports"`
+	Sites        []importSite `json:"sites,omitempty"`
+	Suppressions suppressions `json:"suppressions"`
+}
+
+// importSite is where an import statement is in a source file. Line and
+// Column start at 1.
+type importSite struct {
+	Import    string `json:"import"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Statement string `json:"statement"`
+}
+
+// provenance returns the provenance of the dependency created by the
+// import in the file at path.
+func (i importSite) provenance(path string) dependency.Provenance {
+	kind := dependency.EdgeKindImport
+	if strings.HasPrefix(i.Import, "static ") {
+		kind = dependency.EdgeKindStaticImport
+	}
+
+	return dependency.Provenance{
+		File:      path,
+		Line:      i.Line,
+		Column:    i.Column,
+		Statement: i.Statement,
+		Kind:      kind,
+	}
+}
+
+func extractImportSitesFrom(r io.Reader) ([]importSite, error) {
+	const op = "extractImportSitesFrom(io.Reader)"
+
+	var sites []importSite
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "import ") {
+			statement := strings.TrimSpace(line)
+			if ind := strings.Index(statement, ";"); ind != -1 {
+				statement = statement[:ind+1]
+			}
+
+			imp, err := parseImport(line)
+			if err != nil {
+				return nil, fmt.Errorf("%v: line %d: %v", op, num, err)
+			}
+
+			sites = append(sites, importSite{
+				Import:    imp,
+				Line:      num,
+				Column:    strings.Index(line, "import") + 1,
+				Statement: statement,
+			})
+		}
+
+		if strings.HasPrefix(line, "public") || strings.HasPrefix(line, "class") {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%v: reading from io.Reader: %v", op, err)
+	}
+
+	return sites, nil
+}
+
+// extractSource extracts the source file at path from its content, so
+// that the file only needs to be read once.
+func extractSource(path string, content []byte) (source, error) {
+	const op = "extractSource"
+
+	var s source
+
+	pkg, err := extractPackageFrom(bytes.NewReader(content))
+	if err != nil {
+		return source{}, fmt.Errorf("%v: %q: %v", op, path, err)
+	}
+
+	s.FQCN = fqcnOf(path, pkg)
+
+	if s.Abstract, err = extractAbstractFrom(bytes.NewReader(content)); err != nil {
+		return source{}, fmt.Errorf("%v: %q: %v", op, path, err)
+	}
+
+	if s.Imports, err = extractImportFrom(bytes.NewReader(content)); err != nil {
+		return source{}, fmt.Errorf("%v: %q: %v", op, path, err)
+	}
+
+	if s.Sites, err = extractImportSitesFrom(bytes.NewReader(content)); err != nil {
+		return source{}, fmt.Errorf("%v: %q: %v", op, path, err)
+	}
+
+	if s.Suppressions, err = extractSuppressionsFrom(bytes.NewReader(content)); err != nil {
+		return source{}, fmt.Errorf("%v: %q: %v", op, path, err)
+	}
+
+	return s, nil
 }
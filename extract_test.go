@@ -5,18 +5,31 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/djavorszky/depser/dependency"
 )
 
 const (
 	validImports           = "import com.example.Test;\nimport com.example.util.Something;\nimport hu.coolio.Reader;"
 	validImportsWithPrefix = "package Something;\n" + validImports
 
-	invalidImports = "impor hello.something"
+	invalidImports   = "impor hello.something"
+	malformedImports = "package a;\nimport x\n\npublic class A {\n}"
 
 	validPackage           = "package Something;\n"
 	validPackageWithSuffix = validPackage + validImports
 
-	invalidPackage = "packge hello.something"
+	invalidPackage   = "packge hello.something"
+	malformedPackage = "package a\n"
+
+	concreteClass = validPackageWithSuffix + "\n\n@Component\npublic class Test {\n}"
+	abstractClass = validPackageWithSuffix + "\n\npublic abstract class Test {\n}"
+	interfaceType = validPackageWithSuffix + "\n\npublic interface Test {\n}"
+
+	ignoredImports      = "package Something;\nimport com.example.Test; // depser:ignore-cycle legacy\nimport com.example.Other;\n\npublic class Test {\n}"
+	suppressedClass     = validPackageWithSuffix + "\n\n@SuppressDependency(\"framework\")\npublic class Test {\n}"
+	inlineSuppressClass = validPackageWithSuffix + "\n\n@SuppressDependency(\"legacy\") public class Test {\n\t@SuppressDependency(\"inner\")\n\tclass Inner {}\n}"
+	lateSuppressClass   = validPackageWithSuffix + "\n\npublic class Test {\n\t@SuppressDependency(\"inner\")\n\tclass Inner {}\n}"
 )
 
 func Test_extractImportFrom(t *testing.T) {
@@ -35,6 +48,7 @@ func Test_extractImportFrom(t *testing.T) {
 			[]string{"com.example.Test", "com.example.util.Something", "hu.coolio.Reader"}, false},
 
 		{"invalid", args{strings.NewReader(invalidImports)}, []string{}, false},
+		{"malformed", args{strings.NewReader(malformedImports)}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -50,6 +64,31 @@ func Test_extractImportFrom(t *testing.T) {
 	}
 }
 
+func Test_extractImportSitesFrom(t *testing.T) {
+	src := "package a;\n\nimport b.B; // depser:ignore-cycle legacy\nimport static c.C.run;\n\npublic class A {\n}\nimport d.D;\n"
+
+	got, err := extractImportSitesFrom(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("extractImportSitesFrom() error = %v", err)
+	}
+
+	want := []importSite{
+		{Import: "b.B", Line: 3, Column: 1, Statement: "import b.B;"},
+		{Import: "static c.C.run", Line: 4, Column: 1, Statement: "import static c.C.run;"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractImportSitesFrom() = %+v, want %+v", got, want)
+	}
+
+	if kind := got[1].provenance("A.java").Kind; kind != dependency.EdgeKindStaticImport {
+		t.Errorf("provenance() kind of a static import = %q, want %q", kind, dependency.EdgeKindStaticImport)
+	}
+
+	if _, err := extractImportSitesFrom(strings.NewReader(malformedImports)); err == nil {
+		t.Errorf("extractImportSitesFrom() of a malformed import succeeded")
+	}
+}
+
 func Test_extractPackageFrom(t *testing.T) {
 	type args struct {
 		r io.Reader
@@ -63,7 +102,8 @@ func Test_extractPackageFrom(t *testing.T) {
 		{"valid", args{strings.NewReader(validPackage)}, "Something", false},
 		{"valid with suffix", args{strings.NewReader(validPackageWithSuffix)}, "Something", false},
 
-		{"invalid", args{strings.NewReader(invalidPackage)}, "", false}}
+		{"invalid", args{strings.NewReader(invalidPackage)}, "", false},
+		{"malformed", args{strings.NewReader(malformedPackage)}, "", true}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := extractPackageFrom(tt.args.r)
@@ -77,3 +117,66 @@ func Test_extractPackageFrom(t *testing.T) {
 		})
 	}
 }
+
+func Test_extractAbstractFrom(t *testing.T) {
+	type args struct {
+		r io.Reader
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{"concrete", args{strings.NewReader(concreteClass)}, false, false},
+		{"abstract", args{strings.NewReader(abstractClass)}, true, false},
+		{"interface", args{strings.NewReader(interfaceType)}, true, false},
+
+		{"no declaration", args{strings.NewReader(validImports)}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractAbstractFrom(tt.args.r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractAbstractFrom() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("extractAbstractFrom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractSuppressionsFrom(t *testing.T) {
+	type args struct {
+		r io.Reader
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    suppressions
+		wantErr bool
+	}{
+		{"none", args{strings.NewReader(concreteClass)}, suppressions{Imports: map[string]string{}}, false},
+		{"import comment", args{strings.NewReader(ignoredImports)},
+			suppressions{Imports: map[string]string{"com.example.Test": "legacy"}}, false},
+		{"class annotation", args{strings.NewReader(suppressedClass)},
+			suppressions{All: true, Reason: "framework", Imports: map[string]string{}}, false},
+		{"annotation on declaration", args{strings.NewReader(inlineSuppressClass)},
+			suppressions{All: true, Reason: "legacy", Imports: map[string]string{}}, false},
+		{"annotation after declaration", args{strings.NewReader(lateSuppressClass)}, suppressions{Imports: map[string]string{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractSuppressionsFrom(tt.args.r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractSuppressionsFrom() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractSuppressionsFrom() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
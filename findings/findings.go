@@ -0,0 +1,265 @@
+// Package findings brings dependency cycles and rule violations into a
+// common form, so that they can be reported the same way regardless of
+// where they came from.
+package findings
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/djavorszky/depser/baseline"
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/rules"
+)
+
+// Kinds of findings.
+const (
+	KindCycle     = "cycle"
+	KindViolation = "violation"
+)
+
+// Finding is a dependency cycle or a rule violation. Cycles have an ID and
+// list the classes and dependencies making them up, violations have a
+// rule and a single dependency. Breaks is only set for cycles, if a way to
+// break them was asked for.
+type Finding struct {
+	Kind     string   `json:"kind"`
+	ID       string   `json:"id,omitempty"`
+	Rule     string   `json:"rule,omitempty"`
+	Message  string   `json:"message"`
+	Resolved bool     `json:"resolved,omitempty"`
+	Classes  []string `json:"classes,omitempty"`
+	Edges    []Edge   `json:"edges"`
+	Breaks   []Edge   `json:"breaks,omitempty"`
+}
+
+// Edge is a dependency, along with the first import creating it, if
+// known. Provenance lists all of the imports creating it.
+type Edge struct {
+	From       string                  `json:"from"`
+	To         string                  `json:"to"`
+	File       string                  `json:"file,omitempty"`
+	Line       int                     `json:"line,omitempty"`
+	Column     int                     `json:"column,omitempty"`
+	Provenance []dependency.Provenance `json:"provenance,omitempty"`
+}
+
+// location returns where the import creating the edge is, as file:line or
+// file:line:column, or an empty string if it isn't known.
+func (e Edge) location() string {
+	switch {
+	case e.File == "":
+		return ""
+	case e.Line == 0:
+		return e.File
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+}
+
+// Cycles turns the cycles into findings, looking up the imports creating
+// their dependencies in d.
+func Cycles(d *dependency.Dependency, cycles []baseline.Cycle) []Finding {
+	findings := make([]Finding, 0, len(cycles))
+
+	for _, c := range cycles {
+		f := Finding{
+			Kind:    KindCycle,
+			ID:      c.ID,
+			Message: fmt.Sprintf("Dependency cycle between %d classes", len(c.Classes)),
+			Classes: c.Classes,
+			Edges:   make([]Edge, 0, len(c.Edges)),
+		}
+
+		for _, e := range c.Edges {
+			f.Edges = append(f.Edges, locate(d, e.From, e.To))
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// locate returns the edge from from to to, along with the imports creating
+// it as found in d.
+func locate(d *dependency.Dependency, from, to string) Edge {
+	edge := Edge{From: from, To: to, Provenance: d.Provenance(from, to)}
+	if p, ok := rules.Locate(d, from, to); ok {
+		edge.File, edge.Line, edge.Column = p.File, p.Line, p.Column
+	}
+
+	return edge
+}
+
+// Violations turns the violations into findings, taking the message and
+// location from details where one of them matches.
+func Violations(violations []baseline.Violation, details []rules.Violation) []Finding {
+	known := make(map[baseline.Violation]rules.Violation, len(details))
+	for _, v := range details {
+		known[baseline.Violation{Rule: v.Rule, From: v.From, To: v.To}] = v
+	}
+
+	findings := make([]Finding, 0, len(violations))
+
+	for _, v := range violations {
+		f := Finding{
+			Kind:    KindViolation,
+			Rule:    v.Rule,
+			Message: fmt.Sprintf("%s depends on %s", v.From, v.To),
+			Edges:   []Edge{{From: v.From, To: v.To}},
+		}
+
+		if d, ok := known[v]; ok {
+			f.Message = d.Message
+			f.Edges[0].File = d.File
+			f.Edges[0].Line = d.Line
+			f.Edges[0].Column = d.Column
+			f.Edges[0].Provenance = d.Provenance
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// Resolve marks the findings as resolved, and returns them.
+func Resolve(findings []Finding) []Finding {
+	for i := range findings {
+		findings[i].Resolved = true
+	}
+
+	return findings
+}
+
+// SuggestBreaks sets the breaks of the cycles from the feedback arc sets of
+// the same components, looking up the imports to remove in d.
+func SuggestBreaks(d *dependency.Dependency, findings []Finding, sets []dependency.FeedbackArcSet) {
+	breaks := make(map[string][]Edge, len(sets))
+	for _, set := range sets {
+		edges := make([]Edge, 0, len(set.Breaks))
+		for _, b := range set.Breaks {
+			edges = append(edges, locate(d, b.From, b.To))
+		}
+
+		breaks[componentKey(set.Component)] = edges
+	}
+
+	for i, f := range findings {
+		if f.Kind == KindCycle {
+			findings[i].Breaks = breaks[componentKey(f.Classes)]
+		}
+	}
+}
+
+func componentKey(classes []string) string {
+	sorted := append([]string(nil), classes...)
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, "\x00")
+}
+
+// WriteText writes the findings in a human readable form, violations
+// prefixed with their location like compiler errors.
+func WriteText(w io.Writer, findings []Finding) error {
+	bw := bufio.NewWriter(w)
+
+	for _, f := range findings {
+		status := ""
+		if f.Resolved {
+			status = "Resolved "
+		}
+
+		if f.Kind == KindCycle {
+			fmt.Fprintf(bw, "%sDependency cycle %s between %d class(es):\n", status, f.ID, len(f.Classes))
+
+			for _, e := range f.Edges {
+				if loc := e.location(); loc != "" {
+					fmt.Fprintf(bw, "  %s -> %s (%s)\n", e.From, e.To, loc)
+				} else {
+					fmt.Fprintf(bw, "  %s -> %s\n", e.From, e.To)
+				}
+			}
+
+			if len(f.Breaks) != 0 {
+				fmt.Fprintf(bw, "  Removing these %d import(s) breaks it:\n", len(f.Breaks))
+
+				for _, e := range f.Breaks {
+					if loc := e.location(); loc != "" {
+						fmt.Fprintf(bw, "    %s -> %s (%s)\n", e.From, e.To, loc)
+					} else {
+						fmt.Fprintf(bw, "    %s -> %s\n", e.From, e.To)
+					}
+				}
+			}
+
+			continue
+		}
+
+		if loc := f.Edges[0].location(); loc != "" {
+			fmt.Fprintf(bw, "%s: ", loc)
+		}
+
+		fmt.Fprintf(bw, "%sRule violation [%s]: %s\n", status, f.Rule, f.Message)
+	}
+
+	return bw.Flush()
+}
+
+// WriteJSON writes the findings as an indented JSON array.
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(findings)
+}
+
+// WriteCSV writes the findings as CSV with a header row, one row per
+// dependency. Cycles span as many rows as they have dependencies.
+func WriteCSV(w io.Writer, findings []Finding) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"kind", "id", "rule", "resolved", "from", "to", "file", "line", "column", "message"})
+	for _, f := range findings {
+		for _, e := range f.Edges {
+			line, column := "", ""
+			if e.Line != 0 {
+				line = strconv.Itoa(e.Line)
+			}
+
+			if e.Column != 0 {
+				column = strconv.Itoa(e.Column)
+			}
+
+			cw.Write([]string{
+				f.Kind,
+				f.ID,
+				f.Rule,
+				strconv.FormatBool(f.Resolved),
+				e.From,
+				e.To,
+				e.File,
+				line,
+				column,
+				f.Message,
+			})
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,184 @@
+package findings
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/djavorszky/depser/baseline"
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/rules"
+)
+
+var testProvenance = dependency.Provenance{
+	File:      "src/a/A.java",
+	Line:      3,
+	Column:    1,
+	Statement: "import b.B;",
+	Kind:      dependency.EdgeKindImport,
+}
+
+func testFindings() []Finding {
+	d := dependency.NewWithCycles(true)
+	d.SetNode(dependency.Node{Name: "a.A", File: "src/a/A.java"})
+	d.Add("a.A", "b.B")
+	d.Add("b.B", "a.A")
+	d.Add("a.A", "c.C")
+	d.AddProvenance("a.A", "b.B", testProvenance)
+
+	details := []rules.Violation{
+		{Rule: "no-c", From: "a.A", To: "c.C", File: "src/a/A.java", Line: 4, Message: "a must not use c"},
+	}
+
+	current := baseline.New(d, details)
+
+	findings := Cycles(d, current.Cycles)
+	findings = append(findings, Violations(current.Violations, details)...)
+
+	return findings
+}
+
+func TestCycles(t *testing.T) {
+	f := testFindings()[0]
+
+	if f.Kind != KindCycle || f.ID == "" {
+		t.Fatalf("Cycles() = %+v, want a cycle with an id", f)
+	}
+
+	want := []Edge{
+		{From: "a.A", To: "b.B", File: "src/a/A.java", Line: 3, Column: 1, Provenance: []dependency.Provenance{testProvenance}},
+		{From: "b.B", To: "a.A"},
+	}
+	if !reflect.DeepEqual(f.Edges, want) {
+		t.Errorf("Cycles() edges = %+v, want %+v", f.Edges, want)
+	}
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		name    string
+		details []rules.Violation
+		want    Finding
+	}{
+		{
+			name:    "with details",
+			details: []rules.Violation{{Rule: "r", From: "a", To: "b", File: "A.java", Line: 3, Message: "nope"}},
+			want: Finding{
+				Kind:    KindViolation,
+				Rule:    "r",
+				Message: "nope",
+				Edges:   []Edge{{From: "a", To: "b", File: "A.java", Line: 3}},
+			},
+		},
+		{
+			name: "without details",
+			want: Finding{
+				Kind:    KindViolation,
+				Rule:    "r",
+				Message: "a depends on b",
+				Edges:   []Edge{{From: "a", To: "b"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Violations([]baseline.Violation{{Rule: "r", From: "a", To: "b"}}, tt.details)
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("Violations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestBreaks(t *testing.T) {
+	findings := []Finding{{Kind: KindCycle, Classes: []string{"b", "a"}}, {Kind: KindViolation}}
+	sets := []dependency.FeedbackArcSet{{
+		Component: []string{"a", "b"},
+		Breaks:    []dependency.CycleBreak{{From: "b", To: "a"}},
+	}}
+
+	d := dependency.NewWithCycles(true)
+	d.Add("a", "b")
+	d.Add("b", "a")
+	p := dependency.Provenance{File: "b.java", Line: 3, Column: 1, Statement: "import a;", Kind: dependency.EdgeKindImport}
+	d.AddProvenance("b", "a", p)
+
+	SuggestBreaks(d, findings, sets)
+
+	want := []Edge{{From: "b", To: "a", File: "b.java", Line: 3, Column: 1, Provenance: []dependency.Provenance{p}}}
+	if !reflect.DeepEqual(findings[0].Breaks, want) {
+		t.Errorf("cycle breaks = %+v, want %+v", findings[0].Breaks, want)
+	}
+
+	if findings[1].Breaks != nil {
+		t.Errorf("violation breaks = %+v, want none", findings[1].Breaks)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	findings := testFindings()
+	Resolve(findings[1:])
+	findings[0].Breaks = []Edge{{From: "a.A", To: "b.B", File: "src/a/A.java", Line: 3, Column: 1}}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, findings); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"Dependency cycle " + findings[0].ID + " between 2 class(es):\n  a.A -> b.B (src/a/A.java:3:1)\n  b.B -> a.A\n",
+		"  Removing these 1 import(s) breaks it:\n    a.A -> b.B (src/a/A.java:3:1)\n",
+		"src/a/A.java:4: Resolved Rule violation [no-c]: a must not use c\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteText() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("WriteJSON(nil) = %s, want []", got)
+	}
+
+	buf.Reset()
+
+	findings := testFindings()
+	if err := WriteJSON(&buf, findings); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var got []Finding
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, findings) {
+		t.Errorf("WriteJSON() round trip = %+v, want %+v", got, findings)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testFindings()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("WriteCSV() wrote %d lines, want a header and 3 rows:\n%s", len(lines), buf.String())
+	}
+
+	if want := "violation,,no-c,false,a.A,c.C,src/a/A.java,4,,a must not use c"; lines[3] != want {
+		t.Errorf("WriteCSV() last row = %q, want %q", lines[3], want)
+	}
+}
@@ -0,0 +1,149 @@
+package findings
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+// Names of the JUnit test suites written by WriteJUnit.
+const (
+	JUnitCycleSuite = "depser.acyclic"
+	JUnitRuleSuite  = "depser.rules"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// WriteJUnit writes the findings as a JUnit XML report, so that CI servers
+// can show them along with the tests. There is a test case for every
+// package in the acyclicity suite, which fails if any of its classes are
+// in a cycle, and one for every rule in the rules suite, which fails if
+// the rule is violated. Packages and rules list the test cases that pass
+// when there are no findings for them. Resolved findings are left out.
+func WriteJUnit(w io.Writer, findings []Finding, packages, rules []string) error {
+	cycles := make(map[string][]Finding)
+	violations := make(map[string][]Finding)
+
+	for _, p := range packages {
+		cycles[p] = nil
+	}
+
+	for _, r := range rules {
+		violations[r] = nil
+	}
+
+	for _, f := range findings {
+		if f.Resolved {
+			continue
+		}
+
+		if f.Kind != KindCycle {
+			violations[f.Rule] = append(violations[f.Rule], f)
+			continue
+		}
+
+		seen := make(map[string]struct{})
+		for _, class := range f.Classes {
+			p := dependency.PackageOf(class)
+			if _, ok := seen[p]; !ok {
+				seen[p] = struct{}{}
+				cycles[p] = append(cycles[p], f)
+			}
+		}
+	}
+
+	report := junitSuites{Name: "depser"}
+	for _, suite := range []junitSuite{
+		junitSuiteOf(JUnitCycleSuite, cycles, "cycle"),
+		junitSuiteOf(JUnitRuleSuite, violations, "violation"),
+	} {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// junitSuiteOf creates a suite with a test case for each name, failing
+// with the findings for it.
+func junitSuiteOf(name string, findings map[string][]Finding, kind string) junitSuite {
+	names := make([]string, 0, len(findings))
+	for n := range findings {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	suite := junitSuite{Name: name, Cases: make([]junitCase, 0, len(names))}
+	for _, n := range names {
+		c := junitCase{Name: n, ClassName: name}
+
+		if found := findings[n]; len(found) != 0 {
+			c.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d %s(s) found", len(found), kind),
+				Type:    kind,
+				Text:    junitText(found),
+			}
+
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	return suite
+}
+
+// junitText describes the findings the way WriteText does.
+func junitText(findings []Finding) string {
+	var b strings.Builder
+
+	// Writing to a strings.Builder can't fail.
+	WriteText(&b, findings)
+
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package findings
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	findings := []Finding{
+		{
+			Kind:    KindCycle,
+			ID:      "abc",
+			Classes: []string{"a.A", "a.B", "b.C"},
+			Edges:   []Edge{{From: "a.A", To: "b.C"}, {From: "b.C", To: "a.B"}, {From: "a.B", To: "a.A"}},
+		},
+		{
+			Kind:    KindViolation,
+			Rule:    "no-c",
+			Message: "a must not use c",
+			Edges:   []Edge{{From: "a.A", To: "c.C", File: "src/a/A.java", Line: 4}},
+		},
+		{
+			Kind:     KindViolation,
+			Rule:     "gone",
+			Resolved: true,
+			Edges:    []Edge{{From: "a.A", To: "d.D"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, findings, []string{"a", "c"}, []string{"no-c", "ok"}); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("WriteJUnit() wrote no XML header")
+	}
+
+	var report junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("WriteJUnit() wrote invalid XML: %v", err)
+	}
+
+	if report.Tests != 5 || report.Failures != 3 {
+		t.Errorf("WriteJUnit() = %d tests with %d failures, want 5 with 3", report.Tests, report.Failures)
+	}
+
+	if len(report.Suites) != 2 {
+		t.Fatalf("WriteJUnit() wrote %d suites, want 2", len(report.Suites))
+	}
+
+	failed := make(map[string]bool)
+	for _, s := range report.Suites {
+		for _, c := range s.Cases {
+			failed[s.Name+"/"+c.Name] = c.Failure != nil
+		}
+	}
+
+	want := map[string]bool{
+		JUnitCycleSuite + "/a":   true,
+		JUnitCycleSuite + "/b":   true,
+		JUnitCycleSuite + "/c":   false,
+		JUnitRuleSuite + "/no-c": true,
+		JUnitRuleSuite + "/ok":   false,
+	}
+
+	for name, fails := range want {
+		if got, ok := failed[name]; !ok || got != fails {
+			t.Errorf("test case %s: present = %v, failed = %v, want failed = %v", name, ok, got, fails)
+		}
+	}
+
+	if len(failed) != len(want) {
+		t.Errorf("WriteJUnit() wrote test cases %v, want %v", failed, want)
+	}
+
+	violation := report.Suites[1].Cases[0].Failure
+	if !strings.Contains(violation.Text, "src/a/A.java:4: Rule violation [no-c]: a must not use c") {
+		t.Errorf("violation failure text = %q, want the violation", violation.Text)
+	}
+}
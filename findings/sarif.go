@@ -0,0 +1,236 @@
+package findings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SARIF versions written by WriteSARIF.
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// cycleRule is the rule ID cycles are reported under.
+const cycleRule = "dependency-cycle"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log, so that code
+// scanning tools can show them inline. Each finding is anchored at the
+// import creating its dependency; for cycles that's the first dependency
+// that could be located, with the rest of the cycle as related locations.
+// Resolved findings and the dependencies whose file isn't known are left
+// out, as there is nothing to point at.
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "depser",
+			InformationURI: "https://github.com/djavorszky/depser",
+			Rules:          make([]sarifRule, 0),
+		}},
+		Results: make([]sarifResult, 0),
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, id := range ruleIDs(findings) {
+		ruleIndex[id] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               id,
+			ShortDescription: sarifMessage{ruleDescription(id)},
+		})
+	}
+
+	for _, f := range findings {
+		if f.Resolved {
+			continue
+		}
+
+		id := ruleID(f)
+		result := sarifResult{
+			RuleID:              id,
+			RuleIndex:           ruleIndex[id],
+			Level:               "error",
+			Message:             sarifMessage{f.Message},
+			PartialFingerprints: map[string]string{"depser/v1": fingerprint(f)},
+		}
+
+		for _, e := range f.Edges {
+			if e.File == "" {
+				continue
+			}
+
+			loc := sarifLocation{PhysicalLocation: physicalLocation(e)}
+			if len(result.Locations) == 0 {
+				result.Locations = append(result.Locations, loc)
+				continue
+			}
+
+			related := len(result.RelatedLocations) + 1
+			loc.ID = &related
+			loc.Message = &sarifMessage{fmt.Sprintf("%s -> %s", e.From, e.To)}
+			result.RelatedLocations = append(result.RelatedLocations, loc)
+		}
+
+		if f.Kind == KindCycle {
+			result.Message.Text = fmt.Sprintf("%s: %s", f.Message, cyclePath(f))
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(sarifLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+func ruleID(f Finding) string {
+	if f.Kind == KindCycle {
+		return cycleRule
+	}
+
+	return f.Rule
+}
+
+// ruleIDs returns the sorted IDs of the rules the unresolved findings are
+// reported under.
+func ruleIDs(findings []Finding) []string {
+	seen := make(map[string]struct{})
+	for _, f := range findings {
+		if !f.Resolved {
+			seen[ruleID(f)] = struct{}{}
+		}
+	}
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+func ruleDescription(id string) string {
+	if id == cycleRule {
+		return "Classes must not depend on each other in a cycle"
+	}
+
+	return fmt.Sprintf("Architecture rule %q", id)
+}
+
+// fingerprint identifies a finding across runs, so that code scanning
+// tools can track it even if the lines move.
+func fingerprint(f Finding) string {
+	if f.Kind == KindCycle {
+		return f.ID
+	}
+
+	return fmt.Sprintf("%s:%s->%s", f.Rule, f.Edges[0].From, f.Edges[0].To)
+}
+
+func cyclePath(f Finding) string {
+	edges := make([]string, 0, len(f.Edges))
+	for _, e := range f.Edges {
+		edges = append(edges, e.From+" -> "+e.To)
+	}
+
+	return strings.Join(edges, ", ")
+}
+
+func physicalLocation(e Edge) sarifPhysicalLocation {
+	loc := sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: sarifURI(e.File)},
+	}
+
+	if e.Line != 0 {
+		loc.Region = &sarifRegion{StartLine: e.Line, StartColumn: e.Column}
+	}
+
+	return loc
+}
+
+// sarifURI turns a file path into a URI reference. Relative paths stay
+// relative, so that they resolve against the checkout they were found in.
+func sarifURI(path string) string {
+	uri := url.URL{Path: filepath.ToSlash(path)}
+	if filepath.IsAbs(path) {
+		uri.Scheme = "file"
+
+		if !strings.HasPrefix(uri.Path, "/") {
+			uri.Path = "/" + uri.Path
+		}
+	}
+
+	return uri.String()
+}
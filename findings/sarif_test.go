@@ -0,0 +1,98 @@
+package findings
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	findings := []Finding{
+		{
+			Kind:    KindCycle,
+			ID:      "abc",
+			Message: "Dependency cycle between 2 classes",
+			Classes: []string{"a.A", "b.B"},
+			Edges: []Edge{
+				{From: "a.A", To: "b.B", File: "src/a/A.java", Line: 3, Column: 1},
+				{From: "b.B", To: "a.A", File: "src/b/B.java", Line: 5},
+			},
+		},
+		{
+			Kind:    KindViolation,
+			Rule:    "no-c",
+			Message: "a must not use c",
+			Edges:   []Edge{{From: "a.A", To: "c.C", File: "src/a/A.java", Line: 4}},
+		},
+		{
+			Kind:     KindViolation,
+			Rule:     "gone",
+			Resolved: true,
+			Edges:    []Edge{{From: "a.A", To: "d.D"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, findings); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("WriteSARIF() wrote invalid JSON: %v", err)
+	}
+
+	if log.Version != SARIFVersion || len(log.Runs) != 1 {
+		t.Fatalf("WriteSARIF() = version %q with %d runs, want %q with 1", log.Version, len(log.Runs), SARIFVersion)
+	}
+
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Errorf("WriteSARIF() wrote %d rules, want 2 as resolved findings are left out", len(run.Tool.Driver.Rules))
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("WriteSARIF() wrote %d results, want 2", len(run.Results))
+	}
+
+	cycle := run.Results[0]
+	if cycle.RuleID != cycleRule || run.Tool.Driver.Rules[cycle.RuleIndex].ID != cycleRule {
+		t.Errorf("cycle rule = %q at %d, want %q", cycle.RuleID, cycle.RuleIndex, cycleRule)
+	}
+
+	if len(cycle.Locations) != 1 || *cycle.Locations[0].PhysicalLocation.Region != (sarifRegion{StartLine: 3, StartColumn: 1}) {
+		t.Errorf("cycle locations = %+v, want the first import", cycle.Locations)
+	}
+
+	if len(cycle.RelatedLocations) != 1 || cycle.RelatedLocations[0].PhysicalLocation.ArtifactLocation.URI != "src/b/B.java" {
+		t.Errorf("cycle related locations = %+v, want the second import", cycle.RelatedLocations)
+	}
+
+	if cycle.PartialFingerprints["depser/v1"] != "abc" {
+		t.Errorf("cycle fingerprint = %v, want the cycle id", cycle.PartialFingerprints)
+	}
+
+	violation := run.Results[1]
+	if violation.RuleID != "no-c" || violation.Message.Text != "a must not use c" {
+		t.Errorf("violation = %+v, want rule no-c with its message", violation)
+	}
+}
+
+func Test_sarifURI(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"src/a/A.java", "src/a/A.java"},
+		{"/home/me/src/My App.java", "file:///home/me/src/My%20App.java"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := sarifURI(tt.path); got != tt.want {
+				t.Errorf("sarifURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package git reads other revisions of a local git repository, so that
+// they can be scanned without touching the working tree. It uses the
+// plumbing commands of the git binary, and never the network.
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// TopLevel returns the root of the working tree dir is in.
+func TopLevel(dir string) (string, error) {
+	const op = "git.TopLevel"
+
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%v: %v", op, err)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// ResolveCommit returns the ID of the commit rev names in the repository.
+func ResolveCommit(repo, rev string) (string, error) {
+	const op = "git.ResolveCommit"
+
+	if strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("%v: invalid revision %q", op, rev)
+	}
+
+	out, err := run(repo, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%v: unknown revision %q", op, rev)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// Export writes the files of the revision into dir, at the same paths as
+// they are in the repository. Only the regular files keep returns true
+// for are written; keep gets the slash separated path of the file.
+func Export(repo, rev, dir string, keep func(path string) bool) error {
+	const op = "git.Export"
+
+	commit, err := ResolveCommit(repo, rev)
+	if err != nil {
+		return fmt.Errorf("%v: %v", op, err)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", "-C", repo, "archive", "--format=tar", commit)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("%v: %v", op, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%v: can't run git: %v", op, err)
+	}
+
+	err = extract(tar.NewReader(out), dir, keep)
+
+	// Drain the rest of the archive, so that git can exit.
+	io.Copy(ioutil.Discard, out)
+
+	if werr := cmd.Wait(); werr != nil && err == nil {
+		err = fmt.Errorf("git archive: %v: %s", werr, strings.TrimSpace(stderr.String()))
+	}
+
+	if err != nil {
+		return fmt.Errorf("%v: %v", op, err)
+	}
+
+	return nil
+}
+
+func extract(r *tar.Reader, dir string, keep func(path string) bool) error {
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("reading archive: %v", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !keep(header.Name) {
+			continue
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to write %q outside of %q", header.Name, dir)
+		}
+
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		if err := writeFile(path, r); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// run runs git in dir and returns what it wrote to stdout.
+func run(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+
+	return stdout.String(), nil
+}
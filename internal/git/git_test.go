@@ -0,0 +1,136 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo creates a repository with two commits, and returns its path.
+func testRepo(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("MkdirAll() error = %v", err)
+			}
+
+			if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+		}
+
+		for _, args := range [][]string{
+			{"add", "-A"},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "commit"},
+		} {
+			if _, err := run(dir, args...); err != nil {
+				t.Fatalf("git %v error = %v", args, err)
+			}
+		}
+	}
+
+	if _, err := run(dir, "init", "-q"); err != nil {
+		t.Fatalf("git init error = %v", err)
+	}
+
+	commit(map[string]string{
+		"src/a/A.java": "package a;\n",
+		"README.md":    "readme\n",
+	})
+	commit(map[string]string{
+		"src/a/A.java": "package a;\nimport b.B;\n",
+		"src/b/B.java": "package b;\n",
+	})
+
+	return dir
+}
+
+func TestExport(t *testing.T) {
+	repo := testRepo(t)
+	defer os.RemoveAll(repo)
+
+	dir, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keep := func(path string) bool {
+		return strings.HasSuffix(path, ".java")
+	}
+
+	if err := Export(repo, "HEAD~1", dir, keep); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "src", "a", "A.java"))
+	if err != nil || string(got) != "package a;\n" {
+		t.Errorf("exported A.java = %q, %v, want the first version", got, err)
+	}
+
+	for _, name := range []string{"README.md", filepath.Join("src", "b", "B.java")} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was exported, want it skipped", name)
+		}
+	}
+
+	if err := Export(repo, "no-such-branch", dir, keep); err == nil {
+		t.Errorf("Export() of an unknown revision expected error")
+	}
+}
+
+func TestTopLevel(t *testing.T) {
+	repo := testRepo(t)
+	defer os.RemoveAll(repo)
+
+	got, err := TopLevel(filepath.Join(repo, "src", "a"))
+	if err != nil {
+		t.Fatalf("TopLevel() error = %v", err)
+	}
+
+	want, _ := filepath.EvalSymlinks(repo)
+	if got, _ = filepath.EvalSymlinks(got); got != want {
+		t.Errorf("TopLevel() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	repo := testRepo(t)
+	defer os.RemoveAll(repo)
+
+	tests := []struct {
+		name    string
+		rev     string
+		wantErr bool
+	}{
+		{"head", "HEAD", false},
+		{"parent", "HEAD~1", false},
+		{"unknown", "nope", true},
+		{"option", "--all", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveCommit(repo, tt.rev)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveCommit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && len(got) != 40 {
+				t.Errorf("ResolveCommit() = %q, want a commit id", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+// Package yaml parses the subset of YAML that depser's configuration files
+// use: block mappings and sequences, flow sequences of scalars, plain and
+// quoted scalars, and comments. Anchors, multi-line strings, flow mappings
+// and multiple documents are not supported.
+//
+// Mappings are returned as map[string]interface{}, sequences as
+// []interface{}, and scalars as strings.
+package yaml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type line struct {
+	num    int
+	indent int
+	text   string
+}
+
+type parser struct {
+	lines []line
+}
+
+// Parse reads a single YAML document. An empty document results in an
+// empty mapping.
+func Parse(r io.Reader) (interface{}, error) {
+	const op = "yaml.Parse"
+
+	var p parser
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		raw := scanner.Text()
+
+		text := strings.TrimRight(stripComment(raw), " \t")
+		if strings.TrimSpace(text) == "" || text == "---" {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(text, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("%v: line %d: tabs are not allowed for indentation", op, num)
+		}
+
+		p.lines = append(p.lines, line{num: num, indent: len(text) - len(trimmed), text: trimmed})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%v: reading from io.Reader: %v", op, err)
+	}
+
+	if len(p.lines) == 0 {
+		return make(map[string]interface{}), nil
+	}
+
+	v, next, err := p.block(0, p.lines[0].indent)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", op, err)
+	}
+
+	if next != len(p.lines) {
+		return nil, fmt.Errorf("%v: line %d: unexpected indentation", op, p.lines[next].num)
+	}
+
+	return v, nil
+}
+
+// String returns value as a string, or an error naming key if it isn't
+// one.
+func String(key string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+
+	return s, nil
+}
+
+// StringList accepts either a single string, or a list of them.
+func StringList(key string, value interface{}) ([]string, error) {
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a string or a list of strings", key)
+	}
+
+	var list []string
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string or a list of strings", key)
+		}
+
+		list = append(list, s)
+	}
+
+	return list, nil
+}
+
+// block parses the mapping or sequence starting at line i.
+func (p *parser) block(i, indent int) (interface{}, int, error) {
+	if isSequenceItem(p.lines[i].text) {
+		return p.sequence(i, indent)
+	}
+
+	return p.mapping(i, indent)
+}
+
+func (p *parser) sequence(i, indent int) (interface{}, int, error) {
+	seq := make([]interface{}, 0)
+
+	for i < len(p.lines) && p.lines[i].indent == indent && isSequenceItem(p.lines[i].text) {
+		l := p.lines[i]
+		rest := strings.TrimLeft(l.text[1:], " ")
+
+		switch {
+		case rest == "":
+			if i+1 == len(p.lines) || p.lines[i+1].indent <= indent {
+				seq = append(seq, "")
+				i++
+				continue
+			}
+
+			v, next, err := p.block(i+1, p.lines[i+1].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			seq = append(seq, v)
+			i = next
+
+		case isMappingEntry(rest):
+			// "- key: value" starts a mapping whose keys line up with key.
+			p.lines[i] = line{num: l.num, indent: indent + len(l.text) - len(rest), text: rest}
+
+			v, next, err := p.mapping(i, p.lines[i].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			seq = append(seq, v)
+			i = next
+
+		default:
+			v, err := scalar(rest, l.num)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			seq = append(seq, v)
+			i++
+		}
+	}
+
+	return seq, i, nil
+}
+
+func (p *parser) mapping(i, indent int) (interface{}, int, error) {
+	m := make(map[string]interface{})
+
+	for i < len(p.lines) && p.lines[i].indent == indent && !isSequenceItem(p.lines[i].text) {
+		l := p.lines[i]
+
+		if !isMappingEntry(l.text) {
+			return nil, 0, fmt.Errorf("line %d: expected \"key: value\", got %q", l.num, l.text)
+		}
+
+		key, rest := splitEntry(l.text)
+
+		if _, ok := m[key]; ok {
+			return nil, 0, fmt.Errorf("line %d: duplicate key %q", l.num, key)
+		}
+
+		if rest != "" {
+			v, err := scalar(rest, l.num)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			m[key] = v
+			i++
+			continue
+		}
+
+		i++
+
+		switch {
+		case i < len(p.lines) && p.lines[i].indent > indent:
+			v, next, err := p.block(i, p.lines[i].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			m[key] = v
+			i = next
+		case i < len(p.lines) && p.lines[i].indent == indent && isSequenceItem(p.lines[i].text):
+			// Sequences may be indented at the same level as their key.
+			v, next, err := p.sequence(i, indent)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			m[key] = v
+			i = next
+		default:
+			m[key] = ""
+		}
+	}
+
+	if i < len(p.lines) && p.lines[i].indent > indent {
+		return nil, 0, fmt.Errorf("line %d: unexpected indentation", p.lines[i].num)
+	}
+
+	return m, i, nil
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isMappingEntry checks whether the text has a "key:" part outside of
+// quotes.
+func isMappingEntry(text string) bool {
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") || strings.HasPrefix(text, "[") {
+		return false
+	}
+
+	ind := strings.Index(text, ":")
+
+	return ind > 0 && (ind == len(text)-1 || text[ind+1] == ' ')
+}
+
+func splitEntry(text string) (string, string) {
+	ind := strings.Index(text, ":")
+
+	return strings.TrimSpace(text[:ind]), strings.TrimSpace(text[ind+1:])
+}
+
+// scalar parses a plain or quoted scalar, or a flow sequence of them.
+func scalar(text string, num int) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("line %d: unterminated flow sequence: %s", num, text)
+		}
+
+		seq := make([]interface{}, 0)
+		for _, item := range splitFlow(text[1 : len(text)-1]) {
+			v, err := scalar(item, num)
+			if err != nil {
+				return nil, err
+			}
+
+			seq = append(seq, v)
+		}
+
+		return seq, nil
+
+	case strings.HasPrefix(text, "\""):
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid double quoted string: %s", num, text)
+		}
+
+		return s, nil
+
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("line %d: invalid single quoted string: %s", num, text)
+		}
+
+		return strings.Replace(text[1:len(text)-1], "''", "'", -1), nil
+
+	case strings.HasPrefix(text, "{"):
+		return nil, fmt.Errorf("line %d: flow mappings are not supported", num)
+	}
+
+	return text, nil
+}
+
+// splitFlow splits the items of a flow sequence on commas that are
+// outside of quotes.
+func splitFlow(text string) []string {
+	var (
+		items []string
+		quote rune
+		start int
+	)
+
+	for i, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+
+	if last := strings.TrimSpace(text[start:]); last != "" || len(items) != 0 {
+		items = append(items, last)
+	}
+
+	return items
+}
+
+// stripComment removes a trailing "# comment" that is outside of quotes.
+func stripComment(text string) string {
+	var quote rune
+
+	for i, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,86 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr bool
+	}{
+		{"empty", "", map[string]interface{}{}, false},
+		{"scalars", "a: b\nc: \"d # e\"\nf: 'it''s'\n# comment\ng: h # trailing",
+			map[string]interface{}{"a": "b", "c": "d # e", "f": "it's", "g": "h"}, false},
+		{"nested", "a:\n  b: c\n  d:\n    e: f",
+			map[string]interface{}{"a": map[string]interface{}{"b": "c", "d": map[string]interface{}{"e": "f"}}}, false},
+		{"sequence", "a:\n  - b\n  - \"c\"",
+			map[string]interface{}{"a": []interface{}{"b", "c"}}, false},
+		{"unindented sequence", "a:\n- b\n- c\nd: e",
+			map[string]interface{}{"a": []interface{}{"b", "c"}, "d": "e"}, false},
+		{"flow sequence", "a: [b, \"c, d\", 'e']\nf: []",
+			map[string]interface{}{"a": []interface{}{"b", "c, d", "e"}, "f": []interface{}{}}, false},
+		{"sequence of mappings", "rules:\n  - name: one\n    from: ..a..\n  - name: two\n    list:\n      - x",
+			map[string]interface{}{"rules": []interface{}{
+				map[string]interface{}{"name": "one", "from": "..a.."},
+				map[string]interface{}{"name": "two", "list": []interface{}{"x"}},
+			}}, false},
+		{"top level sequence", "- a\n- b", []interface{}{"a", "b"}, false},
+		{"empty value", "a:\nb: c", map[string]interface{}{"a": "", "b": "c"}, false},
+
+		{"bad indentation", "a: b\n  c: d", nil, true},
+		{"duplicate key", "a: b\na: c", nil, true},
+		{"tab", "a:\n\tb: c", nil, true},
+		{"not a mapping", "a: b\njust text", nil, true},
+		{"unterminated flow", "a: [b, c", nil, true},
+		{"flow mapping", "a: {b: c}", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringList(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    []string
+		wantErr bool
+	}{
+		{"single", "a", []string{"a"}, false},
+		{"list", []interface{}{"a", "b"}, []string{"a", "b"}, false},
+		{"empty list", []interface{}{}, nil, false},
+
+		{"mapping", map[string]interface{}{"a": "b"}, nil, true},
+		{"list of mappings", []interface{}{map[string]interface{}{"a": "b"}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StringList("key", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StringList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StringList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := String("key", []interface{}{"a"}); err == nil {
+		t.Errorf("String() of a list succeeded")
+	}
+}
@@ -0,0 +1,91 @@
+package depser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// moduleMarkers are the build files whose presence marks the root of
+// a module.
+var moduleMarkers = []string{"bnd.bnd", "build.gradle", "build.gradle.kts", "pom.xml"}
+
+// modules caches the module name of each visited directory, as many
+// files share the same few directories. It is reset before each scan, so
+// that build files added or removed since the last one are noticed.
+var modules sync.Map
+
+// resetModules forgets the cached module names.
+func resetModules() {
+	modules.Range(func(dir, _ interface{}) bool {
+		modules.Delete(dir)
+		return true
+	})
+}
+
+// moduleOf returns the name of the module the file belongs to, which is
+// the name of the closest parent directory holding a build file. It's
+// empty if there isn't one.
+func moduleOf(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+
+	return moduleOfDir(filepath.Dir(abs))
+}
+
+func moduleOfDir(dir string) string {
+	if module, ok := modules.Load(dir); ok {
+		return module.(string)
+	}
+
+	var module string
+	if isModuleRoot(dir) {
+		module = filepath.Base(dir)
+	} else if parent := filepath.Dir(dir); parent != dir {
+		module = moduleOfDir(parent)
+	}
+
+	modules.Store(dir, module)
+
+	return module
+}
+
+// IsModuleMarker returns true if the file name is one of the build files
+// whose presence marks the root of a module.
+func IsModuleMarker(name string) bool {
+	for _, marker := range moduleMarkers {
+		if name == marker {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isModuleRoot(dir string) bool {
+	for _, marker := range moduleMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sourceSetOf returns the name of the source set the file belongs to
+// following the Maven and Gradle src/<name>/java layout, e.g. main or test.
+// It's empty if the file doesn't follow the layout.
+func sourceSetOf(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+
+	for i := len(parts) - 3; i >= 0; i-- {
+		if parts[i] == "src" && parts[i+2] == "java" {
+			return parts[i+1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,91 @@
+package depser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_moduleOf(t *testing.T) {
+	root, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := []string{
+		"modules/foo-api/bnd.bnd",
+		"modules/foo-api/src/main/java/com/foo/api/Foo.java",
+		"modules/foo-impl/build.gradle",
+		"modules/foo-impl/src/main/java/com/foo/impl/FooImpl.java",
+		"loose/com/foo/Loose.java",
+	}
+
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("failed creating %s: %v", f, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"bnd", "modules/foo-api/src/main/java/com/foo/api/Foo.java", "foo-api"},
+		{"gradle", "modules/foo-impl/src/main/java/com/foo/impl/FooImpl.java", "foo-impl"},
+		{"no module", "loose/com/foo/Loose.java", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := moduleOf(filepath.Join(root, tt.path)); got != tt.want {
+				t.Errorf("moduleOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sourceSetOf(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"main", "modules/foo/src/main/java/com/foo/Foo.java", "main"},
+		{"test", "modules/foo/src/test/java/com/foo/FooTest.java", "test"},
+		{"integration", "/abs/foo/src/testIntegration/java/com/foo/FooTest.java", "testIntegration"},
+		{"nested src", "src/main/java/com/foo/src/Foo.java", "main"},
+
+		{"no layout", "loose/com/foo/Loose.java", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sourceSetOf(tt.path); got != tt.want {
+				t.Errorf("sourceSetOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsModuleMarker(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"pom.xml", true},
+		{"build.gradle.kts", true},
+		{"bnd.bnd", true},
+		{"settings.gradle", false},
+		{"Foo.java", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsModuleMarker(tt.name); got != tt.want {
+				t.Errorf("IsModuleMarker() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package depser
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +15,14 @@ func parseImport(line string) (string, error) {
 		return "", fmt.Errorf("%v: import statement not found: %v", op, line)
 	}
 
+	// Drop anything after the semicolon, e.g. a trailing comment.
+	ind := strings.Index(line, ";")
+	if ind == -1 {
+		return "", fmt.Errorf("%v: missing semicolon: %v", op, line)
+	}
+
+	line = line[:ind+1]
+
 	// Line has to be at least 9 characters for it to be a valid import:
 	// import a;
 	if len(line) < 9 {
@@ -25,15 +34,6 @@ func parseImport(line string) (string, error) {
 	return line[7 : len(line)-1], nil
 }
 
-func mustParseImport(line string) string {
-	imp, err := parseImport(line)
-	if err != nil {
-		panic("parse failed: " + err.Error())
-	}
-
-	return imp
-}
-
 func parsePackage(line string) (string, error) {
 	const op = "parsePackage"
 
@@ -43,6 +43,14 @@ func parsePackage(line string) (string, error) {
 		return "", fmt.Errorf("%v: package statement not found: %v", op, line)
 	}
 
+	// Drop anything after the semicolon, e.g. a trailing comment.
+	ind := strings.Index(line, ";")
+	if ind == -1 {
+		return "", fmt.Errorf("%v: missing semicolon: %v", op, line)
+	}
+
+	line = line[:ind+1]
+
 	// Line has to be at least 10 characters for it to be a valid package declaration:
 	// package a;
 	if len(line) < 10 {
@@ -53,11 +61,117 @@ func parsePackage(line string) (string, error) {
 	return line[8 : len(line)-1], nil
 }
 
-func mustParsePackage(line string) string {
-	pkg, err := parsePackage(line)
+// parseAbstract checks whether the line is a type declaration, and if
+// so, whether the declared type is abstract. Interfaces and annotation
+// types count as abstract as well.
+func parseAbstract(line string) (bool, error) {
+	const op = "parseAbstract"
+
+	abstract := false
+	for _, word := range strings.Fields(line) {
+		switch word {
+		case "public", "protected", "private", "static", "final", "sealed", "non-sealed", "strictfp":
+			continue
+		case "abstract":
+			abstract = true
+		case "interface", "@interface":
+			return true, nil
+		case "class", "enum", "record":
+			return abstract, nil
+		default:
+			return false, fmt.Errorf("%v: type declaration not found: %v", op, line)
+		}
+	}
+
+	return false, fmt.Errorf("%v: type declaration not found: %v", op, line)
+}
+
+// parseIgnoreCycle checks whether the line has a depser:ignore-cycle
+// comment, and returns the reason given after it, if any.
+func parseIgnoreCycle(line string) (string, bool) {
+	const marker = "depser:ignore-cycle"
+
+	ind := strings.Index(line, "//")
+	if ind == -1 {
+		return "", false
+	}
+
+	comment := strings.TrimSpace(line[ind+2:])
+	if !strings.HasPrefix(comment, marker) {
+		return "", false
+	}
+
+	return strings.TrimSpace(comment[len(marker):]), true
+}
+
+// parseSuppressDependency checks whether the line is a
+// @SuppressDependency annotation, and returns its reason if so, along with
+// the rest of the line, as the annotation may be on the same line as the
+// declaration.
+func parseSuppressDependency(line string) (string, string, error) {
+	const (
+		op         = "parseSuppressDependency"
+		annotation = "@SuppressDependency"
+	)
+
+	line = strings.TrimSpace(line)
+
+	if !strings.HasPrefix(line, annotation) {
+		return "", "", fmt.Errorf("%v: annotation not found: %v", op, line)
+	}
+
+	after := line[len(annotation):]
+	rest := strings.TrimSpace(after)
+
+	if !strings.HasPrefix(rest, "(") {
+		// Anything but a space means a longer name, e.g. that of another
+		// annotation.
+		if after != "" && after[0] != ' ' && after[0] != '\t' {
+			return "", "", fmt.Errorf("%v: annotation not found: %v", op, line)
+		}
+
+		return "", rest, nil
+	}
+
+	end := closingParen(rest)
+	if end == -1 {
+		return "", "", fmt.Errorf("%v: malformed annotation: %v", op, line)
+	}
+
+	arg := strings.TrimSpace(rest[1:end])
+	rest = strings.TrimSpace(rest[end+1:])
+
+	if arg == "" {
+		return "", rest, nil
+	}
+
+	arg = strings.TrimSpace(strings.TrimPrefix(arg, "value"))
+	arg = strings.TrimSpace(strings.TrimPrefix(arg, "="))
+
+	reason, err := strconv.Unquote(arg)
 	if err != nil {
-		panic("parse failed: " + err.Error())
+		return "", "", fmt.Errorf("%v: reason is not a string literal: %v", op, line)
+	}
+
+	return reason, rest, nil
+}
+
+// closingParen returns the index of the parenthesis closing the one s
+// starts with, skipping the ones in string literals, or -1 if there is
+// none.
+func closingParen(s string) int {
+	inString := false
+
+	for i := 1; i < len(s); i++ {
+		switch {
+		case inString && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inString = !inString
+		case !inString && s[i] == ')':
+			return i
+		}
 	}
 
-	return pkg
+	return -1
 }
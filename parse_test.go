@@ -17,9 +17,12 @@ func Test_parseImport(t *testing.T) {
 		{"working", args{"import com.liferay.test;"}, "com.liferay.test", false},
 		{"working one", args{"import com;"}, "com", false},
 		{"working shortest", args{"import a;"}, "a", false},
+		{"working comment", args{"import com.liferay.test; // depser:ignore-cycle"}, "com.liferay.test", false},
 
 		{"corner case", args{"import;"}, "", true},
 		{"corner case2", args{"import ;"}, "", true},
+		{"no semicolon", args{"import com.acme.Foo"}, "", true},
+		{"no semicolon with comment", args{"import com.acme.Foo // depser:ignore-cycle"}, "", true},
 
 		{"empty", args{""}, "", true},
 		{"no import", args{"public class TestClass {"}, "", true},
@@ -38,28 +41,6 @@ func Test_parseImport(t *testing.T) {
 	}
 }
 
-func Test_mustParseImport(t *testing.T) {
-	type args struct {
-		line string
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{"working", args{"import com.liferay.test;"}, "com.liferay.test"},
-		{"working one", args{"import com;"}, "com"},
-		{"working shortest", args{"import a;"}, "a"},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := mustParseImport(tt.args.line); got != tt.want {
-				t.Errorf("mustParseImport() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func Test_parsePackage(t *testing.T) {
 	type args struct {
 		line string
@@ -75,9 +56,11 @@ func Test_parsePackage(t *testing.T) {
 		{"working one", args{"package com;"}, "com", false},
 		{"working blob", args{"package java.io.*;"}, "java.io.*", false},
 		{"working shortest", args{"package a;"}, "a", false},
+		{"working comment", args{"package com.acme; // comment"}, "com.acme", false},
 
 		{"corner case", args{"package;"}, "", true},
 		{"corner case2", args{"package;"}, "", true},
+		{"no semicolon", args{"package com.acme"}, "", true},
 		{"empty", args{""}, "", true},
 		{"no import", args{"public class TestClass {"}, "", true},
 	}
@@ -95,25 +78,110 @@ func Test_parsePackage(t *testing.T) {
 	}
 }
 
-func Test_mustParsePackage(t *testing.T) {
+func Test_parseAbstract(t *testing.T) {
 	type args struct {
 		line string
 	}
 	tests := []struct {
-		name string
-		args args
-		want string
+		name    string
+		args    args
+		want    bool
+		wantErr bool
 	}{
-		{"working", args{"package com.liferay.test;"}, "com.liferay.test"},
-		{"working shorter", args{"package com.liferay;"}, "com.liferay"},
-		{"working one", args{"package com;"}, "com"},
-		{"working blob", args{"package java.io.*;"}, "java.io.*"},
-		{"working shortest", args{"package a;"}, "a"},
+		{"class", args{"public class TestClass {"}, false, false},
+		{"final class", args{"public final class TestClass {"}, false, false},
+		{"package private class", args{"class TestClass {"}, false, false},
+		{"enum", args{"public enum TestEnum {"}, false, false},
+		{"abstract class", args{"public abstract class TestClass {"}, true, false},
+		{"abstract first", args{"abstract public class TestClass {"}, true, false},
+		{"interface", args{"public interface TestInterface {"}, true, false},
+		{"annotation", args{"public @interface TestAnnotation {"}, true, false},
+
+		{"empty", args{""}, false, true},
+		{"import", args{"import com.liferay.test;"}, false, true},
+		{"method", args{"public static void main(String[] args) {"}, false, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := mustParsePackage(tt.args.line); got != tt.want {
-				t.Errorf("mustParsePackage() = %v, want %v", got, tt.want)
+			got, err := parseAbstract(tt.args.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseAbstract() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseAbstract() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseIgnoreCycle(t *testing.T) {
+	type args struct {
+		line string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		want   string
+		wantOK bool
+	}{
+		{"with reason", args{"import com.liferay.test; // depser:ignore-cycle required by the framework"}, "required by the framework", true},
+		{"without reason", args{"import com.liferay.test; //depser:ignore-cycle"}, "", true},
+
+		{"no comment", args{"import com.liferay.test;"}, "", false},
+		{"other comment", args{"import com.liferay.test; // TODO remove"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseIgnoreCycle(tt.args.line)
+			if ok != tt.wantOK {
+				t.Errorf("parseIgnoreCycle() ok = %v, want %v", ok, tt.wantOK)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseIgnoreCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSuppressDependency(t *testing.T) {
+	type args struct {
+		line string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		want     string
+		wantRest string
+		wantErr  bool
+	}{
+		{"with reason", args{`@SuppressDependency("OSGi needs it")`}, "OSGi needs it", "", false},
+		{"named value", args{`  @SuppressDependency(value = "OSGi needs it")`}, "OSGi needs it", "", false},
+		{"without reason", args{"@SuppressDependency"}, "", "", false},
+		{"empty parens", args{"@SuppressDependency()"}, "", "", false},
+		{"on the declaration", args{`@SuppressDependency("legacy") public class A {`}, "legacy", "public class A {", false},
+		{"without reason on the declaration", args{"@SuppressDependency public class A {"}, "", "public class A {", false},
+		{"parens in reason", args{`@SuppressDependency("see (JIRA-1)") class A {`}, "see (JIRA-1)", "class A {", false},
+		{"escaped quote in reason", args{`@SuppressDependency("a \") b") class A {`}, `a ") b`, "class A {", false},
+
+		{"other annotation", args{`@SuppressWarnings("unchecked")`}, "", "", true},
+		{"not a literal", args{"@SuppressDependency(REASON)"}, "", "", true},
+		{"unclosed", args{`@SuppressDependency("reason"`}, "", "", true},
+		{"longer name", args{"@SuppressDependencyCheck"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := parseSuppressDependency(tt.args.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSuppressDependency() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseSuppressDependency() = %v, want %v", got, tt.want)
+			}
+			if rest != tt.wantRest {
+				t.Errorf("parseSuppressDependency() rest = %q, want %q", rest, tt.wantRest)
 			}
 		})
 	}
@@ -0,0 +1,234 @@
+// Package plan applies refactoring plans, declared in a YAML file, to a
+// dependency graph to see their effect before touching the sources.
+//
+// A plan file looks like this:
+//
+//	steps:
+//	  - move: com.acme.web.Formatter
+//	    to: com.acme.text
+//	  - merge: com.acme.util
+//	    into: com.acme.common
+//	  - split: com.acme.core
+//	    into: com.acme.core.io
+//	    classes: [com.acme.core.Reader, com.acme.core.Writer]
+//	  - remove: com.acme.domain.Order
+//	    to: com.acme.web.Session
+//	  - extract-interface: com.acme.db.OrderStore
+//	    as: com.acme.domain.Orders
+//	    for: [com.acme.domain.Checkout]
+//
+// The steps are applied in order:
+//
+//   - move: moves a class to the package given in to.
+//   - merge: moves every class of a package into the package given in into.
+//   - split: moves the given classes of a package into the package given
+//     in into.
+//   - remove: deletes the dependency of a class on the one given in to.
+//   - extract-interface: introduces the interface given in as, implemented
+//     by the class, and makes the classes given in for (or every class
+//     depending on it, if not set) depend on the interface instead.
+package plan
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/internal/yaml"
+)
+
+// Actions of the steps.
+const (
+	ActionMove             = "move"
+	ActionMerge            = "merge"
+	ActionSplit            = "split"
+	ActionRemove           = "remove"
+	ActionExtractInterface = "extract-interface"
+)
+
+// targetKey and classesKey tell which fields each action takes next to
+// its own.
+var (
+	targetKey = map[string]string{
+		ActionMove:             "to",
+		ActionMerge:            "into",
+		ActionSplit:            "into",
+		ActionRemove:           "to",
+		ActionExtractInterface: "as",
+	}
+	classesKey = map[string]string{
+		ActionSplit:            "classes",
+		ActionExtractInterface: "for",
+	}
+)
+
+// Plan is a list of refactoring steps.
+type Plan struct {
+	Steps []Step
+}
+
+// Step is a single refactoring. Subject is the class or package the
+// action is about, Target is where it goes, and Classes are the classes
+// to split off, or the users of an extracted interface.
+type Step struct {
+	Action  string
+	Subject string
+	Target  string
+	Classes []string
+}
+
+// Load reads the plan from a YAML file.
+func Load(path string) (*Plan, error) {
+	const op = "plan.Load"
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%v: failed to open %q: %v", op, path, err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads the plan from YAML.
+func Parse(r io.Reader) (*Plan, error) {
+	const op = "plan.Parse"
+
+	doc, err := yaml.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", op, err)
+	}
+
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v: expected a mapping at the top level", op)
+	}
+
+	for key := range root {
+		if key != "steps" {
+			return nil, fmt.Errorf("%v: unknown field %q", op, key)
+		}
+	}
+
+	items, ok := root["steps"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v: steps must be a list", op)
+	}
+
+	var p Plan
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: step #%d: expected a mapping", op, i+1)
+		}
+
+		step, err := parseStep(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%v: step #%d: %v", op, i+1, err)
+		}
+
+		p.Steps = append(p.Steps, step)
+	}
+
+	return &p, nil
+}
+
+func parseStep(fields map[string]interface{}) (Step, error) {
+	var s Step
+
+	for key := range fields {
+		if _, ok := targetKey[key]; !ok {
+			continue
+		}
+
+		if s.Action != "" {
+			return s, fmt.Errorf("both %s and %s given", s.Action, key)
+		}
+
+		s.Action = key
+	}
+
+	if s.Action == "" {
+		return s, fmt.Errorf("no action, expected one of %s, %s, %s, %s or %s",
+			ActionMove, ActionMerge, ActionSplit, ActionRemove, ActionExtractInterface)
+	}
+
+	var err error
+	for key, value := range fields {
+		switch key {
+		case s.Action:
+			s.Subject, err = yaml.String(key, value)
+		case targetKey[s.Action]:
+			s.Target, err = yaml.String(key, value)
+		case classesKey[s.Action]:
+			s.Classes, err = yaml.StringList(key, value)
+		default:
+			err = fmt.Errorf("unknown field %q for %s", key, s.Action)
+		}
+
+		if err != nil {
+			return s, err
+		}
+	}
+
+	if s.Subject == "" {
+		return s, fmt.Errorf("%s needs a value", s.Action)
+	}
+
+	// Moving into the default package is allowed, so an empty target only
+	// matters when it was left out.
+	if _, ok := fields[targetKey[s.Action]]; !ok {
+		return s, fmt.Errorf("%s needs %s", s.Action, targetKey[s.Action])
+	}
+
+	if s.Action == ActionSplit && len(s.Classes) == 0 {
+		return s, fmt.Errorf("%s needs %s", s.Action, classesKey[s.Action])
+	}
+
+	return s, nil
+}
+
+// Apply applies the steps of the plan to d in order, stopping at the
+// first one that fails. Use it on a Clone to keep the original graph.
+func (p *Plan) Apply(d *dependency.Dependency) error {
+	const op = "plan.Apply"
+
+	for i, s := range p.Steps {
+		if err := s.Apply(d); err != nil {
+			return fmt.Errorf("%v: step #%d (%v): %v", op, i+1, s, err)
+		}
+	}
+
+	return nil
+}
+
+// Apply applies the step to d.
+func (s Step) Apply(d *dependency.Dependency) error {
+	switch s.Action {
+	case ActionMove:
+		_, err := d.MoveClass(s.Subject, s.Target)
+		return err
+	case ActionMerge:
+		return d.MergePackages(s.Subject, s.Target)
+	case ActionSplit:
+		return d.SplitPackage(s.Subject, s.Target, s.Classes)
+	case ActionRemove:
+		return d.Remove(s.Subject, s.Target)
+	case ActionExtractInterface:
+		return d.ExtractInterface(s.Subject, s.Target, s.Classes)
+	}
+
+	return fmt.Errorf("unknown action %q", s.Action)
+}
+
+// String describes the step the way it was written in the plan.
+func (s Step) String() string {
+	str := fmt.Sprintf("%s %s %s %s", s.Action, s.Subject, targetKey[s.Action], s.Target)
+	if len(s.Classes) > 0 {
+		str += fmt.Sprintf(" %s %s", classesKey[s.Action], strings.Join(s.Classes, ", "))
+	}
+
+	return str
+}
@@ -0,0 +1,146 @@
+package plan
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+const testPlan = `
+steps:
+  - move: a.A
+    to: b
+  - split: c
+    into: c.io
+    classes: [c.Reader, c.Writer]
+  - remove: b.A
+    to: b.B
+  - extract-interface: b.B
+    as: api.IB
+  - merge: d
+    into: b
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse(strings.NewReader(testPlan))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Step{
+		{Action: ActionMove, Subject: "a.A", Target: "b"},
+		{Action: ActionSplit, Subject: "c", Target: "c.io", Classes: []string{"c.Reader", "c.Writer"}},
+		{Action: ActionRemove, Subject: "b.A", Target: "b.B"},
+		{Action: ActionExtractInterface, Subject: "b.B", Target: "api.IB"},
+		{Action: ActionMerge, Subject: "d", Target: "b"},
+	}
+	if !reflect.DeepEqual(p.Steps, want) {
+		t.Errorf("Parse() = %+v, want %+v", p.Steps, want)
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"Unknown top level field", "steps: []\nname: x\n"},
+		{"No steps", "name: x\n"},
+		{"Step not a mapping", "steps:\n  - move\n"},
+		{"No action", "steps:\n  - to: b\n"},
+		{"Two actions", "steps:\n  - move: a.A\n    merge: a\n    to: b\n"},
+		{"No target", "steps:\n  - move: a.A\n"},
+		{"Wrong target field", "steps:\n  - move: a.A\n    into: b\n"},
+		{"Empty subject", "steps:\n  - move:\n    to: b\n"},
+		{"Split without classes", "steps:\n  - split: a\n    into: b\n"},
+		{"Classes on a move", "steps:\n  - move: a.A\n    to: b\n    classes: [a.B]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.yaml)); err == nil {
+				t.Errorf("Parse() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plan")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "plan.yaml")
+	if err := ioutil.WriteFile(path, []byte(testPlan), 0644); err != nil {
+		t.Fatalf("failed writing plan: %v", err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(p.Steps) != 5 {
+		t.Errorf("Load() returned %d steps, want 5", len(p.Steps))
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("Load() of a missing file succeeded")
+	}
+}
+
+func TestPlan_Apply(t *testing.T) {
+	d := dependency.NewWithCycles(true)
+	d.Add("a.A", "b.B")
+	d.Add("b.B", "a.A")
+	d.Add("c.Reader", "b.B")
+	d.Add("c.Writer", "c.Util")
+	d.Add("d.D", "c.Util")
+
+	p, err := Parse(strings.NewReader(testPlan))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if err := p.Apply(d); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	want := []string{"api.IB", "b.A", "b.B", "b.D", "c.Util", "c.io.Reader", "c.io.Writer"}
+	if got := d.Classes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Classes() = %v, want %v", got, want)
+	}
+
+	if got := d.Dependents("api.IB"); !reflect.DeepEqual(got, []string{"b.B", "c.io.Reader"}) {
+		t.Errorf("Dependents(api.IB) = %v, want [b.B c.io.Reader]", got)
+	}
+
+	if cycles, ok := d.CheckCyclicDependencies(); !ok {
+		t.Errorf("CheckCyclicDependencies() = %v, want no cycles", cycles)
+	}
+}
+
+func TestPlan_Apply_failingStep(t *testing.T) {
+	d := dependency.NewWithCycles(true)
+	d.Add("a.A", "b.B")
+
+	p := &Plan{Steps: []Step{
+		{Action: ActionRemove, Subject: "a.A", Target: "b.B"},
+		{Action: ActionRemove, Subject: "a.A", Target: "b.B"},
+	}}
+
+	err := p.Apply(d)
+	if err == nil {
+		t.Fatalf("Apply() succeeded, want error")
+	}
+
+	if !strings.Contains(err.Error(), "step #2 (remove a.A to b.B)") {
+		t.Errorf("Apply() error = %v, want it to name the failing step", err)
+	}
+}
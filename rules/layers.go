@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/internal/yaml"
+)
+
+// LayersRule is the name violations of the layered architecture are
+// reported under.
+const LayersRule = "layers"
+
+// Layers is an ordered list of layers, from the top to the bottom. Higher
+// layers may depend on lower ones, but not the other way around. In strict
+// mode, layers may only depend on the layer directly below them.
+//
+// In the rules file they're declared next to the rules:
+//
+//	layers:
+//	  strict: true
+//	  order:
+//	    - name: web
+//	      packages: ..web..
+//	    - name: application
+//	      packages: [..application.., ..service..]
+//	    - name: domain
+//	      packages: ..domain..
+type Layers struct {
+	Strict bool
+	Order  []Layer
+}
+
+// Layer is a named set of packages.
+type Layer struct {
+	Name     string
+	Packages []string
+
+	packages matcher
+}
+
+func parseLayers(value interface{}) (*Layers, error) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping")
+	}
+
+	var l Layers
+	for key, value := range fields {
+		switch key {
+		case "strict":
+			s, err := yaml.String(key, value)
+			if err != nil {
+				return nil, err
+			}
+
+			if s != "true" && s != "false" {
+				return nil, fmt.Errorf("strict must be true or false")
+			}
+
+			l.Strict = s == "true"
+		case "order":
+			items, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("order must be a list")
+			}
+
+			for i, item := range items {
+				layer, err := parseLayer(item)
+				if err != nil {
+					return nil, fmt.Errorf("layer #%d: %v", i+1, err)
+				}
+
+				l.Order = append(l.Order, layer)
+			}
+		default:
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+	}
+
+	if len(l.Order) < 2 {
+		return nil, fmt.Errorf("at least two layers are needed")
+	}
+
+	return &l, nil
+}
+
+func parseLayer(item interface{}) (Layer, error) {
+	var l Layer
+
+	fields, ok := item.(map[string]interface{})
+	if !ok {
+		return l, fmt.Errorf("expected a mapping")
+	}
+
+	for key, value := range fields {
+		var err error
+
+		switch key {
+		case "name":
+			l.Name, err = yaml.String(key, value)
+		case "packages":
+			l.Packages, err = yaml.StringList(key, value)
+		default:
+			err = fmt.Errorf("unknown field %q", key)
+		}
+
+		if err != nil {
+			return l, err
+		}
+	}
+
+	if l.Name == "" || len(l.Packages) == 0 {
+		return l, fmt.Errorf("layers need a name and packages")
+	}
+
+	var err error
+	l.packages, err = compileMatcher(l.Packages)
+
+	return l, err
+}
+
+// layerOf returns the index of the first layer the package belongs to,
+// or -1 if it doesn't belong to any.
+func (l *Layers) layerOf(pkg string) int {
+	for i, layer := range l.Order {
+		if layer.packages.matches(pkg) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// check returns a message explaining the violation, and true, if the
+// dependency from -> to goes against the layering.
+func (l *Layers) check(from, to string) (string, bool) {
+	fromLayer := l.layerOf(dependency.PackageOf(from))
+	toLayer := l.layerOf(dependency.PackageOf(to))
+
+	if fromLayer == -1 || toLayer == -1 {
+		return "", false
+	}
+
+	switch {
+	case toLayer < fromLayer:
+		return fmt.Sprintf("%s in layer %s must not depend on %s in the higher layer %s",
+			from, l.Order[fromLayer].Name, to, l.Order[toLayer].Name), true
+	case l.Strict && toLayer > fromLayer+1:
+		return fmt.Sprintf("%s in layer %s must not skip layer %s to depend on %s in layer %s",
+			from, l.Order[fromLayer].Name, l.Order[fromLayer+1].Name, to, l.Order[toLayer].Name), true
+	}
+
+	return "", false
+}
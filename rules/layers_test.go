@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+const testLayers = `
+layers:
+  strict: %s
+  order:
+    - name: web
+      packages: ..web..
+    - name: application
+      packages: [..application..]
+    - name: domain
+      packages: ..domain..
+`
+
+func TestLayers(t *testing.T) {
+	d := dependency.NewWithCycles(true)
+	d.Add("com.acme.web.Controller", "com.acme.application.UseCase")
+	d.Add("com.acme.web.Controller", "com.acme.domain.Entity")
+	d.Add("com.acme.application.UseCase", "com.acme.domain.Entity")
+	d.Add("com.acme.domain.Entity", "com.acme.application.Event")
+	d.Add("com.acme.domain.Entity", "java.util.List")
+
+	upward := Violation{Rule: LayersRule, From: "com.acme.domain.Entity", To: "com.acme.application.Event",
+		Message: "com.acme.domain.Entity in layer domain must not depend on com.acme.application.Event in the higher layer application"}
+	skip := Violation{Rule: LayersRule, From: "com.acme.web.Controller", To: "com.acme.domain.Entity",
+		Message: "com.acme.web.Controller in layer web must not skip layer application to depend on com.acme.domain.Entity in layer domain"}
+
+	tests := []struct {
+		name   string
+		strict string
+		want   []Violation
+	}{
+		{"relaxed", "false", []Violation{upward}},
+		{"strict", "true", []Violation{upward, skip}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(strings.NewReader(strings.Replace(testLayers, "%s", tt.strict, 1)))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := c.Check(d); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.Check() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_invalidLayers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not a mapping", "layers: a"},
+		{"one layer", "layers:\n  order:\n    - name: a\n      packages: a.."},
+		{"no packages", "layers:\n  order:\n    - name: a\n    - name: b\n      packages: b.."},
+		{"bad strict", "layers:\n  strict: maybe\n  order:\n    - name: a\n      packages: a..\n    - name: b\n      packages: b.."},
+		{"unknown top level", "layerz: a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); err == nil {
+				t.Errorf("Parse() expected error")
+			}
+		})
+	}
+}
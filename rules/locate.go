@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+// Locate returns where the first import creating the dependency from -> to
+// is, and false if from wasn't declared in the scanned sources. Graphs
+// saved before provenance was recorded don't know, in which case the
+// import is looked up in the file of from, with a line of 0 if it can't be
+// found.
+func Locate(d *dependency.Dependency, from, to string) (dependency.Provenance, bool) {
+	if records := d.Provenance(from, to); len(records) != 0 {
+		return records[0], true
+	}
+
+	n, ok := d.Node(from)
+	if !ok {
+		return dependency.Provenance{}, false
+	}
+
+	return dependency.Provenance{File: n.File, Line: ImportLine(n.File, to), Kind: dependency.EdgeKindImport}, true
+}
+
+// ImportLine returns the number of the line in file that imports class,
+// or 0 if it can't be found.
+func ImportLine(file, class string) int {
+	if file == "" {
+		return 0
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Ignore anything after the semicolon, e.g. a trailing comment.
+		if ind := strings.Index(line, ";"); ind != -1 {
+			line = line[:ind+1]
+		}
+
+		if line == "import "+class+";" || line == "import static "+class+";" {
+			return num
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,43 @@
+package rules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "A.java")
+	src := "package a;\n\nimport b.B;\nimport static c.C.run; // needed\n\npublic class A {}\n"
+	if err := ioutil.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		file  string
+		class string
+		want  int
+	}{
+		{"import", file, "b.B", 3},
+		{"static import with comment", file, "c.C.run", 4},
+		{"not imported", file, "d.D", 0},
+		{"missing file", filepath.Join(dir, "B.java"), "b.B", 0},
+		{"no file", "", "b.B", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImportLine(tt.file, tt.class); got != tt.want {
+				t.Errorf("ImportLine(%q) = %d, want %d", tt.class, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compilePattern turns an ArchUnit style package pattern into a regular
+// expression matching package names. A ".." matches any number of
+// packages, including none, and a "*" matches any part of a single
+// package name, e.g.
+//
+//	..domain..        com.acme.domain, com.acme.domain.model
+//	com.acme.api..    com.acme.api, com.acme.api.v1
+//	com.*.internal    com.acme.internal
+//	..                any package
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty package pattern")
+	}
+
+	if pattern == ".." {
+		return regexp.MustCompile(`^.*$`), nil
+	}
+
+	parts := strings.Split(pattern, "..")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i, part := range parts {
+		if i > 0 {
+			switch {
+			case i == 1 && parts[0] == "":
+				expr.WriteString(`(?:.*\.)?`)
+			case i == len(parts)-1 && part == "":
+				expr.WriteString(`(?:\..*)?`)
+			default:
+				expr.WriteString(`\.(?:.*\.)?`)
+			}
+		}
+
+		quoted := regexp.QuoteMeta(part)
+		expr.WriteString(strings.Replace(quoted, `\*`, `[^.]*`, -1))
+	}
+
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid package pattern %q: %v", pattern, err)
+	}
+
+	return re, nil
+}
+
+// matcher matches packages against any of a set of patterns.
+type matcher []*regexp.Regexp
+
+func compileMatcher(patterns []string) (matcher, error) {
+	var m matcher
+	for _, p := range patterns {
+		re, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+
+		m = append(m, re)
+	}
+
+	return m, nil
+}
+
+func (m matcher) matches(pkg string) bool {
+	for _, re := range m {
+		if re.MatchString(pkg) {
+			return true
+		}
+	}
+
+	return false
+}
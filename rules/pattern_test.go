@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"testing"
+)
+
+func Test_compilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		pkg     string
+		want    bool
+	}{
+		{"..domain..", "com.acme.domain", true},
+		{"..domain..", "com.acme.domain.model", true},
+		{"..domain..", "domain", true},
+		{"..domain..", "com.acme.domainx", false},
+		{"..domain..", "com.acme.mydomain.model", false},
+		{"com.acme.api..", "com.acme.api", true},
+		{"com.acme.api..", "com.acme.api.v1", true},
+		{"com.acme.api..", "com.acme.apis", false},
+		{"com.acme.api", "com.acme.api.v1", false},
+		{"com..internal", "com.internal", true},
+		{"com..internal", "com.acme.foo.internal", true},
+		{"com..internal", "org.acme.internal", false},
+		{"com.*.internal", "com.acme.internal", true},
+		{"com.*.internal", "com.acme.foo.internal", false},
+		{"..*Impl", "com.acme.ServiceImpl", true},
+		{"..", "com.acme", true},
+		{"..", "domain", true},
+		{"..", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.pkg, func(t *testing.T) {
+			re, err := compilePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("compilePattern() error = %v", err)
+			}
+
+			if got := re.MatchString(tt.pkg); got != tt.want {
+				t.Errorf("compilePattern(%q) matches %q = %v, want %v (%s)", tt.pattern, tt.pkg, got, tt.want, re)
+			}
+		})
+	}
+
+	if _, err := compilePattern(""); err == nil {
+		t.Errorf("compilePattern() expected error for empty pattern")
+	}
+}
@@ -0,0 +1,343 @@
+// Package rules evaluates architecture rules, declared in a YAML file,
+// against a dependency graph.
+//
+// A rules file looks like this:
+//
+//	rules:
+//	  - name: domain is independent
+//	    type: forbid
+//	    from: ..domain..
+//	    to: [..infrastructure.., ..web..]
+//	  - name: core api
+//	    type: module-api
+//	    module: core
+//	    packages: com.acme.api..
+//	  - name: internals stay internal
+//	    type: internal
+//
+// The supported rule types are:
+//
+//   - forbid: classes in packages matching from must not depend on classes
+//     in packages matching to.
+//   - module-api: classes of module (or of any module, if not set) may only
+//     be used from other modules if their package matches packages.
+//   - internal: classes in a package with an internal segment (or the one
+//     given in segment) may only be used from within the package that
+//     contains that segment.
+//
+// A layered architecture can be declared next to the rules as well, see
+// Layers.
+package rules
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/djavorszky/depser/dependency"
+	"github.com/djavorszky/depser/internal/yaml"
+)
+
+// Rule types.
+const (
+	TypeForbid    = "forbid"
+	TypeModuleAPI = "module-api"
+	TypeInternal  = "internal"
+)
+
+// Config is a set of rules, and optionally a layered architecture.
+type Config struct {
+	Rules  []Rule
+	Layers *Layers
+}
+
+// Rule is a single architecture rule. Which fields are used depends on
+// the type of the rule.
+type Rule struct {
+	Name     string
+	Type     string
+	From     []string
+	To       []string
+	Module   string
+	Packages []string
+	Segment  string
+
+	from, to, packages matcher
+}
+
+// Violation is a dependency that breaks a rule. File, Line and Column
+// point to the first import creating the dependency, and Provenance lists
+// all of them, if known. Reason is only set for suppressed violations, see
+// Config.Suppressed.
+type Violation struct {
+	Rule       string                  `json:"rule"`
+	From       string                  `json:"from"`
+	To         string                  `json:"to"`
+	File       string                  `json:"file,omitempty"`
+	Line       int                     `json:"line,omitempty"`
+	Column     int                     `json:"column,omitempty"`
+	Provenance []dependency.Provenance `json:"provenance,omitempty"`
+	Message    string                  `json:"message"`
+	Reason     string                  `json:"reason,omitempty"`
+}
+
+// Load reads the rules from a YAML file.
+func Load(path string) (*Config, error) {
+	const op = "rules.Load"
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%v: failed to open %q: %v", op, path, err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads the rules from YAML.
+func Parse(r io.Reader) (*Config, error) {
+	const op = "rules.Parse"
+
+	doc, err := yaml.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", op, err)
+	}
+
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v: expected a mapping at the top level", op)
+	}
+
+	for key := range root {
+		if key != "rules" && key != "layers" {
+			return nil, fmt.Errorf("%v: unknown field %q", op, key)
+		}
+	}
+
+	items, ok := root["rules"].([]interface{})
+	if !ok && root["rules"] != nil {
+		return nil, fmt.Errorf("%v: rules must be a list", op)
+	}
+
+	var c Config
+
+	if value, ok := root["layers"]; ok {
+		c.Layers, err = parseLayers(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: layers: %v", op, err)
+		}
+	}
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: rule #%d: expected a mapping", op, i+1)
+		}
+
+		rule, err := parseRule(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%v: rule #%d: %v", op, i+1, err)
+		}
+
+		c.Rules = append(c.Rules, rule)
+	}
+
+	return &c, nil
+}
+
+func parseRule(fields map[string]interface{}) (Rule, error) {
+	var (
+		r   Rule
+		err error
+	)
+
+	for key, value := range fields {
+		switch key {
+		case "name":
+			r.Name, err = yaml.String(key, value)
+		case "type":
+			r.Type, err = yaml.String(key, value)
+		case "module":
+			r.Module, err = yaml.String(key, value)
+		case "segment":
+			r.Segment, err = yaml.String(key, value)
+		case "from":
+			r.From, err = yaml.StringList(key, value)
+		case "to":
+			r.To, err = yaml.StringList(key, value)
+		case "packages":
+			r.Packages, err = yaml.StringList(key, value)
+		default:
+			err = fmt.Errorf("unknown field %q", key)
+		}
+
+		if err != nil {
+			return r, err
+		}
+	}
+
+	if r.Name == "" {
+		r.Name = r.Type
+	}
+
+	switch r.Type {
+	case TypeForbid:
+		if len(r.From) == 0 || len(r.To) == 0 {
+			return r, fmt.Errorf("%s rule needs both from and to", r.Type)
+		}
+
+		if r.from, err = compileMatcher(r.From); err != nil {
+			return r, err
+		}
+
+		r.to, err = compileMatcher(r.To)
+	case TypeModuleAPI:
+		if len(r.Packages) == 0 {
+			return r, fmt.Errorf("%s rule needs packages", r.Type)
+		}
+
+		r.packages, err = compileMatcher(r.Packages)
+	case TypeInternal:
+		if r.Segment == "" {
+			r.Segment = "internal"
+		}
+	default:
+		err = fmt.Errorf("unknown rule type %q", r.Type)
+	}
+
+	return r, err
+}
+
+// Names returns the names violations of the config are reported under, in
+// the order the rules are checked.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Rules)+1)
+	for _, r := range c.Rules {
+		names = append(names, r.Name)
+	}
+
+	if c.Layers != nil {
+		names = append(names, LayersRule)
+	}
+
+	return names
+}
+
+// Check evaluates every rule against every dependency in the graph and
+// returns the violations, ordered by rule, then by dependency. Layer
+// violations come last. Suppressed dependencies are left out.
+func (c *Config) Check(d *dependency.Dependency) []Violation {
+	return c.check(d, false)
+}
+
+// Suppressed returns the violations that Check leaves out because the
+// dependencies were suppressed, along with the reason they were.
+func (c *Config) Suppressed(d *dependency.Dependency) []Violation {
+	return c.check(d, true)
+}
+
+func (c *Config) check(d *dependency.Dependency, suppressed bool) []Violation {
+	var violations []Violation
+
+	for _, r := range c.Rules {
+		r := r
+		violations = append(violations, collect(d, r.Name, suppressed, func(from, to string) (string, bool) {
+			return r.check(d, from, to)
+		})...)
+	}
+
+	if c.Layers != nil {
+		violations = append(violations, collect(d, LayersRule, suppressed, c.Layers.check)...)
+	}
+
+	return violations
+}
+
+// collect runs check on every dependency that is or isn't suppressed, and
+// turns the ones it reports into violations of the named rule.
+func collect(d *dependency.Dependency, rule string, suppressed bool, check func(from, to string) (string, bool)) []Violation {
+	var violations []Violation
+
+	for _, from := range d.Classes() {
+		for _, to := range d.Dependencies(from) {
+			reason, ok := d.Suppressed(from, to)
+			if ok != suppressed {
+				continue
+			}
+
+			msg, ok := check(from, to)
+			if !ok {
+				continue
+			}
+
+			v := Violation{Rule: rule, From: from, To: to, Message: msg, Reason: reason}
+			if p, ok := Locate(d, from, to); ok {
+				v.File, v.Line, v.Column = p.File, p.Line, p.Column
+			}
+
+			v.Provenance = d.Provenance(from, to)
+
+			violations = append(violations, v)
+		}
+	}
+
+	return violations
+}
+
+// check returns a message explaining the violation, and true, if the
+// dependency from -> to breaks the rule.
+func (r Rule) check(d *dependency.Dependency, from, to string) (string, bool) {
+	fromPkg, toPkg := dependency.PackageOf(from), dependency.PackageOf(to)
+
+	switch r.Type {
+	case TypeForbid:
+		if r.from.matches(fromPkg) && r.to.matches(toPkg) {
+			return fmt.Sprintf("%s must not depend on %s", from, to), true
+		}
+
+	case TypeModuleAPI:
+		fromNode, ok := d.Node(from)
+		if !ok {
+			return "", false
+		}
+
+		toNode, ok := d.Node(to)
+		if !ok || toNode.Module == "" || toNode.Module == fromNode.Module {
+			return "", false
+		}
+
+		if r.Module != "" && toNode.Module != r.Module {
+			return "", false
+		}
+
+		if !r.packages.matches(toPkg) {
+			return fmt.Sprintf("%s is not part of the API of module %s, but is used from module %s",
+				to, toNode.Module, fromNode.Module), true
+		}
+
+	case TypeInternal:
+		parent, ok := internalParent(toPkg, r.Segment)
+		if !ok || parent == "" {
+			return "", false
+		}
+
+		if fromPkg != parent && !strings.HasPrefix(fromPkg, parent+".") {
+			return fmt.Sprintf("%s is internal to %s, but is used from %s", to, parent, from), true
+		}
+	}
+
+	return "", false
+}
+
+// internalParent returns the package enclosing the first segment named
+// segment in pkg, e.g. com.acme for com.acme.internal.util.
+func internalParent(pkg, segment string) (string, bool) {
+	parts := strings.Split(pkg, ".")
+	for i, part := range parts {
+		if part == segment {
+			return strings.Join(parts[:i], "."), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,152 @@
+package rules
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+const testRules = `
+rules:
+  - name: domain is independent
+    type: forbid
+    from: ..domain..
+    to: [..infrastructure..]
+  - name: core api
+    type: module-api
+    module: core
+    packages: com.acme.api..
+  - type: internal
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(c.Rules) != 3 {
+		t.Fatalf("Parse() returned %d rules, want 3", len(c.Rules))
+	}
+
+	if r := c.Rules[0]; r.Name != "domain is independent" || !reflect.DeepEqual(r.To, []string{"..infrastructure.."}) {
+		t.Errorf("unexpected first rule: %+v", r)
+	}
+
+	if r := c.Rules[2]; r.Name != TypeInternal || r.Segment != "internal" {
+		t.Errorf("unexpected defaults: %+v", r)
+	}
+}
+
+func TestConfig_Names(t *testing.T) {
+	c, err := Parse(strings.NewReader(testRules + fmt.Sprintf(testLayers, "false")))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []string{"domain is independent", "core api", TypeInternal, LayersRule}
+	if got := c.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not yaml", "rules: [a"},
+		{"not a list", "rules: a"},
+		{"unknown type", "rules:\n  - type: magic"},
+		{"unknown field", "rules:\n  - type: internal\n    colour: red"},
+		{"forbid without to", "rules:\n  - type: forbid\n    from: a.."},
+		{"api without packages", "rules:\n  - type: module-api"},
+		{"bad list", "rules:\n  - type: forbid\n    from:\n      a: b\n    to: c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); err == nil {
+				t.Errorf("Parse() expected error")
+			}
+		})
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depser")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	serviceFile := filepath.Join(dir, "Service.java")
+	ioutil.WriteFile(serviceFile, []byte("package com.acme.domain;\n\nimport java.util.List;\nimport com.acme.infrastructure.Db; // depser:ignore-cycle\n\npublic class Service {}\n"), 0644)
+
+	d := dependency.NewWithCycles(true)
+	d.SetNode(dependency.Node{Name: "com.acme.domain.Service", Module: "core", File: serviceFile})
+	d.SetNode(dependency.Node{Name: "com.acme.infrastructure.Db", Module: "core"})
+	d.SetNode(dependency.Node{Name: "com.acme.api.Api", Module: "core"})
+	d.SetNode(dependency.Node{Name: "com.acme.internal.Helper", Module: "core"})
+	d.SetNode(dependency.Node{Name: "com.web.Controller", Module: "web"})
+	d.SetNode(dependency.Node{Name: "com.acme.domain.Model", Module: "core"})
+
+	d.Add("com.acme.domain.Service", "com.acme.infrastructure.Db")
+	d.Add("com.acme.domain.Service", "java.util.List")
+	d.Add("com.acme.domain.Service", "com.acme.internal.Helper")
+	d.Add("com.web.Controller", "com.acme.api.Api")
+	d.Add("com.web.Controller", "com.acme.domain.Service")
+	d.Add("com.web.Controller", "com.acme.internal.Helper")
+	d.Add("com.web.Controller", "com.acme.domain.Model")
+	d.Suppress("com.web.Controller", "com.acme.domain.Model", "legacy")
+
+	c, err := Parse(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := c.Check(d)
+
+	want := []Violation{
+		{Rule: "domain is independent", From: "com.acme.domain.Service", To: "com.acme.infrastructure.Db", File: serviceFile, Line: 4,
+			Message: "com.acme.domain.Service must not depend on com.acme.infrastructure.Db"},
+		{Rule: "core api", From: "com.web.Controller", To: "com.acme.domain.Service",
+			Message: "com.acme.domain.Service is not part of the API of module core, but is used from module web"},
+		{Rule: "core api", From: "com.web.Controller", To: "com.acme.internal.Helper",
+			Message: "com.acme.internal.Helper is not part of the API of module core, but is used from module web"},
+		{Rule: "internal", From: "com.web.Controller", To: "com.acme.internal.Helper",
+			Message: "com.acme.internal.Helper is internal to com.acme, but is used from com.web.Controller"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Config.Check() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestConfig_Suppressed(t *testing.T) {
+	d := dependency.NewWithCycles(true)
+	d.Add("com.acme.domain.Service", "com.acme.infrastructure.Db")
+	d.Add("com.acme.domain.Service", "com.acme.infrastructure.Cache")
+	d.Suppress("com.acme.domain.Service", "com.acme.infrastructure.Cache", "being migrated")
+
+	c, err := Parse(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := c.Check(d); len(got) != 1 || got[0].To != "com.acme.infrastructure.Db" {
+		t.Errorf("Config.Check() = %+v, want only the Db violation", got)
+	}
+
+	want := []Violation{{Rule: "domain is independent", From: "com.acme.domain.Service", To: "com.acme.infrastructure.Cache",
+		Message: "com.acme.domain.Service must not depend on com.acme.infrastructure.Cache", Reason: "being migrated"}}
+
+	if got := c.Suppressed(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config.Suppressed() = %+v, want %+v", got, want)
+	}
+}
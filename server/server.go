@@ -0,0 +1,274 @@
+// Package server exposes a dependency graph over a local HTTP JSON API,
+// along with the HTML report as a browsable UI.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+// maxSearchResults limits the number of classes returned by a search.
+const maxSearchResults = 100
+
+// Server serves a single dependency graph. The graph is not expected to
+// change while it's being served.
+type Server struct {
+	dep *dependency.Dependency
+	mux *http.ServeMux
+
+	report   []byte
+	cycles   [][]string
+	packages []dependency.PackageMetrics
+
+	classesOnce sync.Once
+	classes     []dependency.NodeMetrics
+}
+
+// nodeResponse is what /api/node returns for a class.
+type nodeResponse struct {
+	Name      string `json:"name"`
+	Package   string `json:"package"`
+	Declared  bool   `json:"declared"`
+	File      string `json:"file,omitempty"`
+	Module    string `json:"module,omitempty"`
+	SourceSet string `json:"sourceSet,omitempty"`
+	Abstract  bool   `json:"abstract"`
+
+	Dependencies []string `json:"dependencies"`
+	Dependents   []string `json:"dependents"`
+}
+
+// edgeResponse is what /api/edge returns for a dependency.
+type edgeResponse struct {
+	From       string                  `json:"from"`
+	To         string                  `json:"to"`
+	Sites      int                     `json:"sites"`
+	Provenance []dependency.Provenance `json:"provenance"`
+}
+
+type cyclesResponse struct {
+	Cycles [][]string `json:"cycles"`
+	SCCs   [][]string `json:"sccs"`
+}
+
+// New prepares a Server for the graph. The HTML report, the cycles and
+// the package metrics are calculated upfront. The class metrics are
+// calculated on first request, as they take considerably longer.
+func New(d *dependency.Dependency) (*Server, error) {
+	const op = "server.New"
+
+	s := Server{
+		dep:      d,
+		mux:      http.NewServeMux(),
+		cycles:   make([][]string, 0),
+		packages: d.PackageMetrics(),
+	}
+
+	var report bytes.Buffer
+	if err := d.WriteHTML(&report); err != nil {
+		return nil, fmt.Errorf("%v: failed rendering report: %v", op, err)
+	}
+	s.report = report.Bytes()
+
+	cycles, _ := d.CheckCyclicDependencies()
+	for _, cycle := range cycles {
+		s.cycles = append(s.cycles, strings.Split(cycle, " -> "))
+	}
+
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/api/nodes", s.handleNodes)
+	s.mux.HandleFunc("/api/node", s.handleNode)
+	s.mux.HandleFunc("/api/dependencies", s.handleDependencies)
+	s.mux.HandleFunc("/api/dependents", s.handleDependents)
+	s.mux.HandleFunc("/api/edge", s.handleEdge)
+	s.mux.HandleFunc("/api/path", s.handlePath)
+	s.mux.HandleFunc("/api/cycles", s.handleCycles)
+	s.mux.HandleFunc("/api/metrics/packages", s.handlePackageMetrics)
+	s.mux.HandleFunc("/api/metrics/classes", s.handleClassMetrics)
+
+	return &s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(s.report)
+}
+
+// handleNodes lists the classes whose name contains the q parameter,
+// or all of them if it's missing.
+func (s *Server) handleNodes(w http.ResponseWriter, r *http.Request) {
+	q := strings.ToLower(r.URL.Query().Get("q"))
+
+	matches := make([]string, 0)
+	for _, class := range s.dep.Classes() {
+		if q != "" && !strings.Contains(strings.ToLower(class), q) {
+			continue
+		}
+
+		matches = append(matches, class)
+		if q != "" && len(matches) == maxSearchResults {
+			break
+		}
+	}
+
+	writeJSON(w, matches)
+}
+
+func (s *Server) handleNode(w http.ResponseWriter, r *http.Request) {
+	name, ok := s.requireClass(w, r, "name")
+	if !ok {
+		return
+	}
+
+	n, declared := s.dep.Node(name)
+
+	writeJSON(w, nodeResponse{
+		Name:         name,
+		Package:      dependency.PackageOf(name),
+		Declared:     declared,
+		File:         n.File,
+		Module:       n.Module,
+		SourceSet:    n.SourceSet,
+		Abstract:     n.Abstract,
+		Dependencies: nonNil(s.dep.Dependencies(name)),
+		Dependents:   nonNil(s.dep.Dependents(name)),
+	})
+}
+
+func (s *Server) handleDependencies(w http.ResponseWriter, r *http.Request) {
+	if name, ok := s.requireClass(w, r, "name"); ok {
+		writeJSON(w, nonNil(s.dep.Dependencies(name)))
+	}
+}
+
+func (s *Server) handleDependents(w http.ResponseWriter, r *http.Request) {
+	if name, ok := s.requireClass(w, r, "name"); ok {
+		writeJSON(w, nonNil(s.dep.Dependents(name)))
+	}
+}
+
+func (s *Server) handleEdge(w http.ResponseWriter, r *http.Request) {
+	from, ok := s.requireClass(w, r, "from")
+	if !ok {
+		return
+	}
+
+	to, ok := s.requireClass(w, r, "to")
+	if !ok {
+		return
+	}
+
+	sites := s.dep.ImportSites(from, to)
+	if sites == 0 {
+		http.Error(w, fmt.Sprintf("%s does not directly depend on %s", from, to), http.StatusNotFound)
+		return
+	}
+
+	provenance := s.dep.Provenance(from, to)
+	if provenance == nil {
+		provenance = make([]dependency.Provenance, 0)
+	}
+
+	writeJSON(w, edgeResponse{From: from, To: to, Sites: sites, Provenance: provenance})
+}
+
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	from, ok := s.requireClass(w, r, "from")
+	if !ok {
+		return
+	}
+
+	to, ok := s.requireClass(w, r, "to")
+	if !ok {
+		return
+	}
+
+	path := s.dep.ShortestPath(from, to)
+	if path == nil {
+		http.Error(w, fmt.Sprintf("%s does not depend on %s", from, to), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, path)
+}
+
+func (s *Server) handleCycles(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, cyclesResponse{
+		Cycles: s.cycles,
+		SCCs:   nonNilSCCs(s.dep.StronglyConnectedComponents()),
+	})
+}
+
+func (s *Server) handlePackageMetrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.packages)
+}
+
+func (s *Server) handleClassMetrics(w http.ResponseWriter, r *http.Request) {
+	s.classesOnce.Do(func() {
+		s.classes = s.dep.NodeMetrics()
+	})
+
+	writeJSON(w, s.classes)
+}
+
+// requireClass reads a class name from the given query parameter, and
+// responds with an error if it's missing or unknown.
+func (s *Server) requireClass(w http.ResponseWriter, r *http.Request, param string) (string, bool) {
+	name := r.URL.Query().Get(param)
+	if name == "" {
+		http.Error(w, fmt.Sprintf("missing %q parameter", param), http.StatusBadRequest)
+		return "", false
+	}
+
+	if _, ok := s.dep.Node(name); !ok && len(s.dep.Dependencies(name)) == 0 && len(s.dep.Dependents(name)) == 0 {
+		http.Error(w, fmt.Sprintf("class not found: %s", name), http.StatusNotFound)
+		return "", false
+	}
+
+	return name, true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return make([]string, 0)
+	}
+
+	return list
+}
+
+func nonNilSCCs(sccs [][]string) [][]string {
+	if sccs == nil {
+		return make([][]string, 0)
+	}
+
+	return sccs
+}
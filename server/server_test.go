@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+func testServer(t *testing.T) *Server {
+	d := dependency.NewWithCycles(true)
+	d.SetNode(dependency.Node{Name: "com.a.A", Module: "a"})
+	d.SetNode(dependency.Node{Name: "com.b.B", Module: "b"})
+	d.Add("com.a.A", "com.b.B")
+	d.Add("com.b.B", "com.a.A")
+	d.Add("com.b.B", "java.util.List")
+	d.AddProvenance("com.a.A", "com.b.B", dependency.Provenance{File: "A.java", Line: 3, Column: 1, Statement: "import com.b.B;", Kind: dependency.EdgeKindImport})
+
+	s, err := New(d)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	return s
+}
+
+func TestServer(t *testing.T) {
+	s := testServer(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		wantStatus int
+		want       string
+	}{
+		{"index", "GET", "/", http.StatusOK, "<title>depser report</title>"},
+		{"nodes", "GET", "/api/nodes", http.StatusOK, `["com.a.A","com.b.B","java.util.List"]`},
+		{"nodes search", "GET", "/api/nodes?q=util", http.StatusOK, `["java.util.List"]`},
+		{"node", "GET", "/api/node?name=com.a.A", http.StatusOK, `"module":"a"`},
+		{"dependencies", "GET", "/api/dependencies?name=com.b.B", http.StatusOK, `["com.a.A","java.util.List"]`},
+		{"dependents", "GET", "/api/dependents?name=java.util.List", http.StatusOK, `["com.b.B"]`},
+		{"cyclic dependents", "GET", "/api/dependents?name=com.b.B", http.StatusOK, `["com.a.A"]`},
+		{"edge", "GET", "/api/edge?from=com.a.A&to=com.b.B", http.StatusOK, `"provenance":[{"file":"A.java","line":3,"column":1,"statement":"import com.b.B;","kind":"import"}]`},
+		{"edge without provenance", "GET", "/api/edge?from=com.b.B&to=java.util.List", http.StatusOK, `"sites":1,"provenance":[]`},
+		{"path", "GET", "/api/path?from=com.a.A&to=java.util.List", http.StatusOK, `["com.a.A","com.b.B","java.util.List"]`},
+		{"cycles", "GET", "/api/cycles", http.StatusOK, `"sccs":[["com.a.A","com.b.B"]]`},
+		{"package metrics", "GET", "/api/metrics/packages", http.StatusOK, `"package":"com.a"`},
+		{"class metrics", "GET", "/api/metrics/classes", http.StatusOK, `"class":"com.a.A"`},
+
+		{"unknown page", "GET", "/nope", http.StatusNotFound, ""},
+		{"missing param", "GET", "/api/node", http.StatusBadRequest, "missing"},
+		{"unknown class", "GET", "/api/node?name=com.x.X", http.StatusNotFound, "not found"},
+		{"no edge", "GET", "/api/edge?from=com.a.A&to=java.util.List", http.StatusNotFound, "does not directly depend"},
+		{"no path", "GET", "/api/path?from=java.util.List&to=com.a.A", http.StatusNotFound, "does not depend"},
+		{"post", "POST", "/api/nodes", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.url, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_node(t *testing.T) {
+	rec := httptest.NewRecorder()
+	testServer(t).ServeHTTP(rec, httptest.NewRequest("GET", "/api/node?name=java.util.List", nil))
+
+	var got nodeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed decoding response: %v", err)
+	}
+
+	want := nodeResponse{
+		Name:         "java.util.List",
+		Package:      "java.util",
+		Dependencies: []string{},
+		Dependents:   []string{"com.b.B"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("node = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,256 @@
+package depser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+// Tree keeps the dependency graph of a set of source roots up to date as
+// the files under them change. Only the changed files are parsed, and only
+// the dependencies they declare are updated.
+type Tree struct {
+	roots   []string
+	options Options
+	dep     *dependency.Dependency
+	files   map[string]treeFile
+	classes map[string]string
+}
+
+// treeFile is a source file as it was when it was last parsed, and the
+// module it was in.
+type treeFile struct {
+	size    int64
+	modTime int64
+	module  string
+	source  source
+}
+
+// Changes are the source files that changed since the tree was last
+// updated. Modified includes the new files.
+type Changes struct {
+	Modified []string
+	Deleted  []string
+
+	infos map[string]os.FileInfo
+}
+
+// Empty returns true if no files changed.
+func (c Changes) Empty() bool {
+	return len(c.Modified) == 0 && len(c.Deleted) == 0
+}
+
+// NewTree scans the source roots, as set up by options.
+func NewTree(roots []string, options Options) (*Tree, error) {
+	const op = "NewTree"
+
+	if err := options.validate(); err != nil {
+		return nil, fmt.Errorf("%v: %v", op, err)
+	}
+
+	t := Tree{
+		roots:   roots,
+		options: options,
+		dep:     dependency.NewWithCycles(options.AllowCycles),
+		files:   make(map[string]treeFile),
+		classes: make(map[string]string),
+	}
+
+	changes, err := t.Changes()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", op, err)
+	}
+
+	if err := t.Apply(changes); err != nil {
+		return nil, fmt.Errorf("%v: %v", op, err)
+	}
+
+	return &t, nil
+}
+
+// Graph returns the dependency graph of the tree. It is changed in place
+// by Apply; Clone it to keep a copy. If the options drop or collapse
+// external classes, Graph returns a reduced copy instead, as the tree needs
+// every import to stay up to date.
+func (t *Tree) Graph() *dependency.Dependency {
+	if !t.options.reducesExternal() {
+		return t.dep
+	}
+
+	dep := t.dep.Clone()
+
+	// The options were validated by NewTree, so this can't fail.
+	ReduceExternal(dep, t.options)
+
+	return dep
+}
+
+// Changes looks for the source files that were added, modified or deleted
+// since the tree was last updated, going by their size and modification
+// time. Files that moved to another module, as a build file was added or
+// removed, count as modified too.
+func (t *Tree) Changes() (Changes, error) {
+	const op = "Tree.Changes"
+
+	c := Changes{infos: make(map[string]os.FileInfo)}
+
+	resetModules()
+
+	for _, root := range t.roots {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return fmt.Errorf("can't visit %q: %v", path, err)
+			}
+
+			if excluded(t.options.Exclude, path, info) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if info.IsDir() || !strings.HasSuffix(info.Name(), ".java") {
+				return nil
+			}
+
+			c.infos[path] = info
+
+			f, ok := t.files[path]
+			if !ok || f.size != info.Size() || f.modTime != info.ModTime().UnixNano() || f.module != moduleOf(path) {
+				c.Modified = append(c.Modified, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return Changes{}, fmt.Errorf("%v: path %q: %v", op, root, err)
+		}
+	}
+
+	for path := range t.files {
+		if _, ok := c.infos[path]; !ok {
+			c.Deleted = append(c.Deleted, path)
+		}
+	}
+
+	sort.Strings(c.Modified)
+	sort.Strings(c.Deleted)
+
+	return c, nil
+}
+
+// Apply parses the modified files and updates the graph with the changes.
+// If any of the files can't be parsed, the graph is left untouched.
+func (t *Tree) Apply(c Changes) error {
+	const op = "Tree.Apply"
+
+	parsed := make(map[string]treeFile, len(c.Modified))
+	for _, path := range c.Modified {
+		info := c.infos[path]
+
+		src, err := readSource(t.options.Cache, path, info)
+		if err != nil {
+			return fmt.Errorf("%v: %v", op, err)
+		}
+
+		parsed[path] = treeFile{
+			size:    info.Size(),
+			modTime: info.ModTime().UnixNano(),
+			module:  moduleOf(path),
+			source:  src,
+		}
+	}
+
+	for _, path := range c.Deleted {
+		if err := t.removeFile(path); err != nil {
+			return fmt.Errorf("%v: %v", op, err)
+		}
+	}
+
+	for _, path := range c.Modified {
+		if old, ok := t.files[path]; ok && old.source.FQCN != parsed[path].source.FQCN {
+			if err := t.removeFile(path); err != nil {
+				return fmt.Errorf("%v: %v", op, err)
+			}
+		}
+
+		t.files[path] = parsed[path]
+		t.classes[parsed[path].source.FQCN] = path
+
+		if err := t.applyFile(path); err != nil {
+			return fmt.Errorf("%v: %v", op, err)
+		}
+	}
+
+	return nil
+}
+
+// applyFile sets the class of the file and its dependencies in the graph.
+func (t *Tree) applyFile(path string) error {
+	f := t.files[path]
+	src := f.source
+
+	err := t.dep.SetNode(dependency.Node{
+		Name:      src.FQCN,
+		File:      path,
+		Module:    f.module,
+		SourceSet: sourceSetOf(path),
+		Abstract:  src.Abstract,
+	})
+	if err != nil {
+		return fmt.Errorf("failed adding class: %v", err)
+	}
+
+	if err := t.dep.SetDependencies(src.FQCN, src.Imports); err != nil {
+		return fmt.Errorf("failed setting dependencies: %v", err)
+	}
+
+	for _, imp := range src.Imports {
+		if reason, ok := src.Suppressions.reasonFor(imp); ok {
+			if err := t.dep.Suppress(src.FQCN, imp, reason); err != nil {
+				return fmt.Errorf("failed suppressing dependency: %v", err)
+			}
+		}
+	}
+
+	return addProvenance(t.dep, path, src)
+}
+
+// removeFile removes the class of the file from the graph. The classes
+// importing it keep depending on it, as they do until they're changed too.
+func (t *Tree) removeFile(path string) error {
+	f, ok := t.files[path]
+	if !ok {
+		return nil
+	}
+
+	delete(t.files, path)
+
+	class := f.source.FQCN
+	if t.classes[class] != path {
+		return nil
+	}
+
+	delete(t.classes, class)
+
+	dependers := t.dep.Dependents(class)
+
+	// The class may have no dependencies left to remove, in which case
+	// there's nothing to do.
+	t.dep.RemoveNode(class)
+
+	for _, depender := range dependers {
+		if p, ok := t.classes[depender]; ok {
+			if err := t.applyFile(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package depser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/djavorszky/depser/dependency"
+)
+
+func TestTree(t *testing.T) {
+	root := writeSources(t, map[string]string{
+		"com/a/A.java": "package com.a;\n\nimport com.b.B;\n\npublic class A {}\n",
+		"com/b/B.java": "package com.b;\n\nimport com.c.C;\n\npublic class B {}\n",
+		"com/c/C.java": "package com.c;\n\npublic class C {}\n",
+	})
+	defer os.RemoveAll(root)
+
+	tree, err := NewTree([]string{root}, Options{AllowCycles: true})
+	if err != nil {
+		t.Fatalf("NewTree() error = %v", err)
+	}
+
+	assertSameAsBuild(t, tree, root)
+
+	// Modification times may not be fine grained enough to tell the
+	// writes apart, so move them forward explicitly.
+	later := time.Now().Add(time.Hour)
+	write := func(name, content string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Errorf("WriteFile() error = %v", err)
+		}
+
+		later = later.Add(time.Second)
+		os.Chtimes(path, later, later)
+	}
+
+	tests := []struct {
+		name         string
+		change       func()
+		wantModified int
+		wantDeleted  int
+	}{
+		{"no changes", func() {}, 0, 0},
+		{"modify", func() {
+			write("com/c/C.java", "package com.c;\n\nimport com.a.A; // depser:ignore-cycle testing\n\npublic class C {}\n")
+		}, 1, 0},
+		{"add", func() {
+			write("com/d/D.java", "package com.d;\n\nimport com.a.A;\n\npublic class D {}\n")
+		}, 1, 0},
+		{"delete imported", func() {
+			os.Remove(filepath.Join(root, "com", "b", "B.java"))
+		}, 0, 1},
+		{"move to another package", func() {
+			write("com/d/D.java", "package com.e;\n\nimport com.c.C;\n\npublic class D {}\n")
+		}, 1, 0},
+		{"add build file", func() {
+			write("com/pom.xml", "<project/>\n")
+		}, 3, 0},
+		{"delete build file", func() {
+			os.Remove(filepath.Join(root, "com", "pom.xml"))
+		}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+
+			changes, err := tree.Changes()
+			if err != nil {
+				t.Fatalf("Tree.Changes() error = %v", err)
+			}
+
+			if len(changes.Modified) != tt.wantModified || len(changes.Deleted) != tt.wantDeleted {
+				t.Errorf("Tree.Changes() = %+v, want %d modified and %d deleted", changes, tt.wantModified, tt.wantDeleted)
+			}
+
+			if err := tree.Apply(changes); err != nil {
+				t.Fatalf("Tree.Apply() error = %v", err)
+			}
+
+			assertSameAsBuild(t, tree, root)
+		})
+	}
+}
+
+// assertSameAsBuild checks that the graph of the tree is the same as if
+// the sources were scanned from scratch.
+func assertSameAsBuild(t *testing.T, tree *Tree, root string) {
+	t.Helper()
+
+	want, err := BuildDependenciesWithOptions([]string{root}, Options{AllowCycles: true})
+	if err != nil {
+		t.Fatalf("BuildDependenciesWithOptions() error = %v", err)
+	}
+
+	got := tree.Graph()
+
+	if !reflect.DeepEqual(got.Classes(), want.Classes()) {
+		t.Fatalf("classes = %v, want %v", got.Classes(), want.Classes())
+	}
+
+	for _, class := range want.Classes() {
+		gotNode, gotOK := got.Node(class)
+		wantNode, wantOK := want.Node(class)
+		if gotNode != wantNode || gotOK != wantOK {
+			t.Errorf("node %s = %+v, want %+v", class, gotNode, wantNode)
+		}
+
+		if !reflect.DeepEqual(got.Dependencies(class), want.Dependencies(class)) {
+			t.Errorf("dependencies of %s = %v, want %v", class, got.Dependencies(class), want.Dependencies(class))
+		}
+
+		for _, dep := range want.Dependencies(class) {
+			if !reflect.DeepEqual(got.Provenance(class, dep), want.Provenance(class, dep)) {
+				t.Errorf("provenance of %s -> %s = %v, want %v", class, dep, got.Provenance(class, dep), want.Provenance(class, dep))
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(got.Suppressions(), want.Suppressions()) {
+		t.Errorf("suppressions = %v, want %v", got.Suppressions(), want.Suppressions())
+	}
+
+	if diff := dependency.Compare(want, got); len(diff.Packages) != 0 {
+		t.Errorf("package metrics differ: %+v", diff.Packages)
+	}
+}
+
+func TestNewTree_invalid(t *testing.T) {
+	if _, err := NewTree([]string{"does-not-exist"}, Options{}); err == nil {
+		t.Errorf("NewTree() of a missing root expected error")
+	}
+
+	if _, err := NewTree(nil, Options{Exclude: []string{"["}}); err == nil {
+		t.Errorf("NewTree() with a bad exclude pattern expected error")
+	}
+}